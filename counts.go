@@ -0,0 +1,62 @@
+package wnram
+
+import "sort"
+
+// The number of times this word was tagged with this meaning in
+// semantic concordance texts, from cntlist.  Zero when unknown.
+func (w *Lookup) Count() int {
+	if word := w.wordRec(); word != nil {
+		return int(word.count)
+	}
+	return 0
+}
+
+// Synonyms ordered from most to least frequently tagged, see Count.
+// Ties keep the order of Synonyms.
+func (w *Lookup) SynonymsByCount() []string {
+	words := make([]wordRec, len(w.h.words(w.c())))
+	copy(words, w.h.words(w.c()))
+	sort.SliceStable(words, func(i, j int) bool {
+		return words[i].count > words[j].count
+	})
+	synonyms := make([]string, 0, len(words))
+	for _, word := range words {
+		synonyms = append(synonyms, w.h.str(word.word))
+	}
+	return synonyms
+}
+
+// How familiar a word is, after WordNet's "wn -famln"
+type Familiarity struct {
+	// The number of senses of the word
+	Polysemy int
+	// From "extremely rare" to "extremely familiar"
+	Level string
+}
+
+var familiarityLevels = []struct {
+	maxPolysemy int
+	level       string
+}{
+	{0, "extremely rare"},
+	{1, "very rare"},
+	{2, "rare"},
+	{4, "uncommon"},
+	{8, "common"},
+	{16, "familiar"},
+	{32, "very familiar"},
+}
+
+// Familiarity measures how familiar a word is as the given part of
+// speech, based on its polysemy.
+func (h *Handle) Familiarity(word string, pos PartOfSpeech) Familiarity {
+	found, _ := h.Lookup(Criteria{Matching: word, POS: PartOfSpeechList{pos}})
+	f := Familiarity{Polysemy: len(found), Level: "extremely familiar"}
+	for _, l := range familiarityLevels {
+		if f.Polysemy <= l.maxPolysemy {
+			f.Level = l.level
+			break
+		}
+	}
+	return f
+}
@@ -0,0 +1,17 @@
+// Package data embeds the WordNet database files bundled with wnram, so
+// that programs work without shipping the data directory.  Only the
+// verb, adjective and adverb data files are bundled, so the other parts
+// of speech must be left out when loading, which drops the relations
+// to them:
+//
+//	wn, err := wnram.NewFSWithOptions(data.FS, ".", wnram.Options{
+//		POS: wnram.PartOfSpeechList{wnram.Verb, wnram.Adjective, wnram.Adverb},
+//	})
+package data
+
+import "embed"
+
+// The WordNet database files in this directory
+//
+//go:embed data.*
+var FS embed.FS
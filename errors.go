@@ -0,0 +1,119 @@
+package wnram
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Causes of load errors, which may be tested for with errors.Is
+var (
+	// A pointer symbol which is not one of the relations
+	ErrUnknownPointer = errors.New("unrecognized pointer type")
+	// A relation to a synset which is not in the data files
+	ErrDanglingRelation = errors.New("relation to unknown synset")
+	// An index or sense index entry for a synset which is not in the
+	// data files
+	ErrUnknownSynset = errors.New("unknown synset")
+	// A relation or verb frame for a word number beyond the words of
+	// its synset
+	ErrUnknownWord = errors.New("unknown word number")
+	// A sense key in index.sense which differs from the one computed
+	// from the data files
+	ErrSenseKeyMismatch = errors.New("sense key mismatch")
+)
+
+// A ParseError reports a problem with the contents of a file, located as
+// precisely as possible.
+type ParseError struct {
+	File   string // the name of the file
+	Line   int64  // the line number, from 1, or 0 if not known
+	Offset int64  // the byte offset of the line within the file
+	Column int    // the byte position of Token within the line, from 1, or 0 if not known
+	Token  string // the offending token, if any
+	Err    error  // the cause
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.File == "":
+		return e.Err.Error()
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Err)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// The errors found by a lenient load (see Options.Lenient), by file and
+// line.  errors.Is and errors.As consider each of them.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+func (l ErrorList) sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].File != l[j].File {
+			return l[i].File < l[j].File
+		}
+		return l[i].Line < l[j].Line
+	})
+}
+
+// A syntaxError locates an error within a line, see ParseError
+type syntaxError struct {
+	column int
+	token  string
+	err    error
+}
+
+func (e *syntaxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *syntaxError) Unwrap() error {
+	return e.err
+}
+
+// syntaxErr reports an error in the token of line at which l was
+// positioned before lexing it.
+func syntaxErr(line []byte, l lexable, err error) error {
+	rest := strings.TrimLeft(string(l), " \t")
+	token := rest
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		token = rest[:i]
+	}
+	return &syntaxError{column: len(line) - len(rest) + 1, token: token, err: err}
+}
+
+// parseError locates an error in a line of a file
+func parseError(filename string, line, offset int64, err error) *ParseError {
+	e := &ParseError{File: filename, Line: line, Offset: offset, Err: err}
+	var se *syntaxError
+	if errors.As(err, &se) {
+		e.Column, e.Token, e.Err = se.column, se.token, se.err
+	}
+	return e
+}
@@ -0,0 +1,73 @@
+package wnram
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var badNouns = []string{
+	"00001740 03 n 01 entity 0 000 | that which exists",
+	"00001850 03 n 01 thing 0 001 ?x 00001740 n 0000 | a bad pointer",
+	"00001930 03 n 01 object 0 001 @ 00009999 n 0000 | a missing hypernym",
+	"00002000 03 n 01 stuff 0 001 @ 00001740 n 0000 | fine",
+}
+
+func writeBadNouns(t *testing.T) string {
+	dir := t.TempDir()
+	data := strings.Join(badNouns, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "data.noun"), []byte(data), 0644); err != nil {
+		t.Fatalf("%s", err)
+	}
+	return dir
+}
+
+func TestParseError(t *testing.T) {
+	_, err := New(writeBadNouns(t))
+	if !errors.Is(err, ErrUnknownPointer) {
+		t.Fatalf("expected an unknown pointer, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a ParseError, got %T", err)
+	}
+	offset := int64(len(badNouns[0]) + 1)
+	column := strings.Index(badNouns[1], "?x") + 1
+	if pe.File != "data.noun" || pe.Line != 2 || pe.Offset != offset || pe.Column != column || pe.Token != "?x" {
+		t.Errorf("unexpected location of error: %+v", pe)
+	}
+	if want := `data.noun:2:`; !strings.HasPrefix(err.Error(), want) || !strings.Contains(err.Error(), `"?x"`) {
+		t.Errorf("unexpected error message: %s", err)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	h, err := NewWithOptions(writeBadNouns(t), Options{Lenient: true})
+	if h == nil {
+		t.Fatalf("expected a database despite errors: %s", err)
+	}
+	var errs ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if !errors.Is(err, ErrUnknownPointer) || !errors.Is(err, ErrDanglingRelation) {
+		t.Errorf("expected an unknown pointer and a dangling relation, got %v", err)
+	}
+	if errs[1].Line != 3 || errs[1].Token != "00009999" {
+		t.Errorf("unexpected location of dangling relation: %+v", errs[1])
+	}
+
+	for word, hypernyms := range map[string]int{"entity": 0, "object": 0, "stuff": 1} {
+		found, _ := h.Lookup(Criteria{Matching: word})
+		if len(found) != 1 {
+			t.Fatalf("expected to find %s", word)
+		} else if n := len(found[0].Related(Hypernym)); n != hypernyms {
+			t.Errorf("expected %d hypernyms of %s, got %d", hypernyms, word, n)
+		}
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "thing"}); len(found) != 0 {
+		t.Errorf("expected the malformed line to be skipped")
+	}
+}
@@ -0,0 +1,141 @@
+package wnram
+
+import (
+	"sort"
+	"strings"
+)
+
+// The generic sentence frames for verbs, indexed by frame number.
+// "----" stands for the verb.
+var verbFrames = [...]string{
+	1:  "Something ----s",
+	2:  "Somebody ----s",
+	3:  "It is ----ing",
+	4:  "Something is ----ing PP",
+	5:  "Something ----s something Adjective/Noun",
+	6:  "Something ----s Adjective/Noun",
+	7:  "Somebody ----s Adjective",
+	8:  "Somebody ----s something",
+	9:  "Somebody ----s somebody",
+	10: "Something ----s somebody",
+	11: "Something ----s something",
+	12: "Something ----s to somebody",
+	13: "Somebody ----s on something",
+	14: "Somebody ----s somebody something",
+	15: "Somebody ----s something to somebody",
+	16: "Somebody ----s something from somebody",
+	17: "Somebody ----s somebody with something",
+	18: "Somebody ----s somebody of something",
+	19: "Somebody ----s something on somebody",
+	20: "Somebody ----s somebody PP",
+	21: "Somebody ----s something PP",
+	22: "Somebody ----s PP",
+	23: "Somebody's (body part) ----s",
+	24: "Somebody ----s somebody to INFINITIVE",
+	25: "Somebody ----s somebody INFINITIVE",
+	26: "Somebody ----s that CLAUSE",
+	27: "Somebody ----s to somebody",
+	28: "Somebody ----s to INFINITIVE",
+	29: "Somebody ----s whether INFINITIVE",
+	30: "Somebody ----s somebody into V-ing something",
+	31: "Somebody ----s something with something",
+	32: "Somebody ----s INFINITIVE",
+	33: "Somebody ----s VERB-ing",
+	34: "It ----s that CLAUSE",
+	35: "Something ----s INFINITIVE",
+}
+
+// VerbFrame returns the generic sentence frame with the given number,
+// e.g. "Somebody ----s something" for frame 8, or the empty string
+// for an unknown frame.
+func VerbFrame(n int) string {
+	if n <= 0 || n >= len(verbFrames) {
+		return ""
+	}
+	return verbFrames[n]
+}
+
+// inflect adds an "s" or "ing" suffix to a verb following the common
+// spelling rules.
+func inflect(verb, suffix string) string {
+	switch suffix {
+	case "s":
+		if strings.HasSuffix(verb, "y") && len(verb) > 1 && !strings.ContainsRune("aeiou", rune(verb[len(verb)-2])) {
+			return verb[:len(verb)-1] + "ies"
+		}
+		for _, sibilant := range []string{"s", "x", "z", "ch", "sh"} {
+			if strings.HasSuffix(verb, sibilant) {
+				return verb + "es"
+			}
+		}
+	case "ing":
+		if strings.HasSuffix(verb, "ie") {
+			return verb[:len(verb)-2] + "ying"
+		}
+		if strings.HasSuffix(verb, "e") && !strings.HasSuffix(verb, "ee") && len(verb) > 2 {
+			return verb[:len(verb)-1] + "ing"
+		}
+	}
+	return verb + suffix
+}
+
+// fillFrame substitutes a verb into a generic frame.  For phrasal
+// verbs only the first word is inflected ("Somebody looks up
+// something").
+func fillFrame(frame, verb string) string {
+	i := strings.Index(frame, "----")
+	if i < 0 {
+		return frame
+	}
+	rest := frame[i+4:]
+	suffix := rest
+	if j := strings.IndexByte(rest, ' '); j >= 0 {
+		suffix = rest[:j]
+	}
+	rest = rest[len(suffix):]
+	head, particle := verb, ""
+	if j := strings.IndexByte(verb, ' '); j >= 0 {
+		head, particle = verb[:j], verb[j:]
+	}
+	return frame[:i] + inflect(head, suffix) + particle + rest
+}
+
+// The numbers of the generic sentence frames this verb may be used in,
+// in ascending order.  See VerbFrame.
+func (w *Lookup) VerbFrameNumbers() []int {
+	var frames []int
+	c := w.c()
+	for _, f := range w.h.frames[c.frames : c.frames+uint32(c.nFrames)] {
+		frames = append(frames, int(f))
+	}
+	if word := w.wordRec(); word != nil {
+		for _, f := range w.h.frames[word.frames : word.frames+uint32(word.nFrames)] {
+			frames = append(frames, int(f))
+		}
+	}
+	sort.Ints(frames)
+	return frames
+}
+
+// The sentence frames this verb may be used in, filled in with the
+// word, e.g. "Somebody runs something".  When sentidx.vrb and sents.vrb
+// were present at load time, example sentences for this sense follow
+// the generic frames.  Returns nothing for other parts of speech.
+func (w *Lookup) VerbFrames() (frames []string) {
+	verb := w.Word()
+	word := w.wordRec()
+	for _, n := range w.VerbFrameNumbers() {
+		if f := VerbFrame(n); f != "" {
+			frames = append(frames, fillFrame(f, verb))
+		}
+	}
+	if word != nil {
+		for _, n := range w.h.sentences[word.sentences : word.sentences+uint32(word.nSentences)] {
+			if int(n) < len(w.h.verbSentences) && w.h.verbSentences[n].n > 0 {
+				s := w.h.str(w.h.verbSentences[n])
+				frames = append(frames, strings.Replace(s, "%s", verb, -1))
+			}
+		}
+	}
+	return frames
+}
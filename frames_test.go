@@ -0,0 +1,49 @@
+package wnram
+
+import "testing"
+
+func TestFillFrame(t *testing.T) {
+	cases := []struct {
+		frame, verb, want string
+	}{
+		{VerbFrame(8), "run", "Somebody runs something"},
+		{VerbFrame(3), "rain", "It is raining"},
+		{VerbFrame(2), "cry", "Somebody cries"},
+		{VerbFrame(2), "catch", "Somebody catches"},
+		{VerbFrame(8), "look up", "Somebody looks up something"},
+		{VerbFrame(4), "move", "Something is moving PP"},
+	}
+	for _, c := range cases {
+		if got := fillFrame(c.frame, c.verb); got != c.want {
+			t.Errorf("fillFrame(%q, %q) = %q, want %q", c.frame, c.verb, got, c.want)
+		}
+	}
+	if VerbFrame(0) != "" || VerbFrame(36) != "" {
+		t.Errorf("expected no frames outside 1-35")
+	}
+}
+
+func TestVerbFrames(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "eat", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	var frames []string
+	for _, f := range found {
+		frames = append(frames, f.VerbFrames()...)
+	}
+	if !setContains(frames, []string{"Somebody eats", "Somebody eats something"}) {
+		t.Errorf("missing verb frames for eat, got %v", frames)
+	}
+
+	found, err = wnInstance.Lookup(Criteria{Matching: "yummy"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for _, f := range found {
+		if len(f.VerbFrames()) != 0 {
+			t.Errorf("unexpected verb frames for yummy")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package wnram
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitGloss separates a gloss into its definition and quoted example
+// sentences, e.g. `a young dog; "the pup wagged its tail"`.  Semicolons
+// inside quotes do not separate parts, and attributions after an
+// example's closing quote are dropped.
+func splitGloss(gloss string) (definition string, examples []string) {
+	var defs []string
+	for len(gloss) > 0 {
+		gloss = strings.TrimSpace(gloss)
+		if strings.HasPrefix(gloss, "\"") {
+			end := strings.IndexByte(gloss[1:], '"')
+			if end < 0 {
+				examples = append(examples, strings.TrimSpace(gloss[1:]))
+				break
+			}
+			examples = append(examples, strings.TrimSpace(gloss[1:end+1]))
+			gloss = gloss[end+2:]
+			if i := strings.IndexByte(gloss, ';'); i >= 0 {
+				gloss = gloss[i+1:]
+			} else {
+				gloss = ""
+			}
+			continue
+		}
+		part := gloss
+		if i := strings.IndexByte(gloss, ';'); i >= 0 {
+			part, gloss = gloss[:i], gloss[i+1:]
+		} else {
+			gloss = ""
+		}
+		if part = strings.TrimSpace(part); part != "" {
+			defs = append(defs, part)
+		}
+	}
+	return strings.Join(defs, "; "), examples
+}
+
+// The definition of this meaning, without example sentences
+func (w *Lookup) Definition() string {
+	def, _ := splitGloss(w.Gloss())
+	return def
+}
+
+// Example sentences illustrating this meaning
+func (w *Lookup) Examples() []string {
+	_, examples := splitGloss(w.Gloss())
+	return examples
+}
+
+// tokenize splits text into lower case words for matching lemmas
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+}
+
+// usesLemma reports whether a sequence of example tokens is an
+// occurrence of the lemma tokens, allowing for inflection.
+func (w *Lookup) usesLemma(tokens, lemma []string) bool {
+	for i, t := range tokens {
+		if t != lemma[i] && !w.inflects(t, lemma[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inflects reports whether token is an inflected form of base
+func (w *Lookup) inflects(token, base string) bool {
+	if w.h == nil {
+		return false
+	}
+	for _, b := range w.h.Lemmatize(token, w.c().pos) {
+		if b == base {
+			return true
+		}
+	}
+	return false
+}
+
+// The example sentences of this meaning which use each of its
+// synonyms, keyed by synonym.  Inflected uses are found, e.g. "ran"
+// is a use of "run".  Synonyms not used in any example are omitted.
+func (w *Lookup) LemmaExamples() map[string][]string {
+	examples := w.Examples()
+	found := map[string][]string{}
+	for _, word := range w.Synonyms() {
+		lemma := tokenize(word)
+		if len(lemma) == 0 {
+			continue
+		}
+		for _, e := range examples {
+			tokens := tokenize(e)
+			for i := 0; i+len(lemma) <= len(tokens); i++ {
+				if w.usesLemma(tokens[i:i+len(lemma)], lemma) {
+					found[word] = append(found[word], e)
+					break
+				}
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,195 @@
+package wnram
+
+import (
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The information content of synsets, -log p, where p is the
+// probability of encountering the synset or any of its hyponyms in a
+// corpus.  Synsets are identified by part of speech and offset, so
+// information content applies to any Handle loaded from the same
+// release of WordNet.
+type InformationContent struct {
+	// counts by part of speech and offset, the total being at offset 0
+	counts [AdjectiveSatellite]map[uint32]float64
+}
+
+// The information content NLTK gives synsets which never occur
+const infiniteIC = 1e300
+
+// Load information content in the format of NLTK's wordnet_ic corpus,
+// e.g. ic-brown.dat or ic-semcor.dat, which may be compressed with gzip.
+// Each line but the first gives a count for a synset's offset and part
+// of speech, e.g. "1740n 128767 ROOT", and counts marked ROOT are summed
+// to give the total.
+func LoadInformationContent(fsys fs.FS, name string) (*InformationContent, error) {
+	r, filename, err := openDecompressed(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	ic := &InformationContent{}
+	err = inPlaceReadLine(r, func(line []byte, count, offset int64) error {
+		if count == 1 || len(line) == 0 {
+			return nil // the header
+		}
+		l := lexable(line)
+		at := l
+		synset, err := l.lexDecimalNumber()
+		var pos PartOfSpeech
+		if err == nil {
+			pos, err = l.lexPOS()
+		}
+		if err != nil {
+			return parseError(filename, count, offset, syntaxErr(line, at, err))
+		}
+		at = l
+		value, _ := l.lexWord()
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return parseError(filename, count, offset, syntaxErr(line, at, err))
+		}
+		ic.add(pos, 0, 0)
+		if root, _ := l.lexWord(); root == "ROOT" {
+			ic.counts[pos][0] += n
+		}
+		if n != 0 {
+			ic.counts[pos][uint32(synset)] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ic, nil
+}
+
+// add adds to the count of a synset, creating the table of its part of
+// speech if need be
+func (ic *InformationContent) add(pos PartOfSpeech, offset uint32, n float64) {
+	if ic.counts[pos] == nil {
+		ic.counts[pos] = map[uint32]float64{}
+	}
+	ic.counts[pos][offset] += n
+}
+
+// Options for computing information content, see
+// Handle.ComputeInformationContent
+type ICOptions struct {
+	// Added to the count of every synset, so that none has infinite
+	// information content.  NLTK uses 1 by default.
+	Smoothing float64
+	// Count each occurrence of a word as an occurrence of every one of
+	// its senses, rather than dividing it between them.
+	WholeSenses bool
+}
+
+// Compute information content from the number of times words occur in
+// a corpus.  As in NLTK, each occurrence is shared among the senses of
+// the word in every part of speech, including the base forms of
+// inflected words, and counts for a synset are added to its hypernyms
+// level by level, see hypernymLevels.
+func (h *Handle) ComputeInformationContent(counts map[string]float64, opts ICOptions) *InformationContent {
+	ic := &InformationContent{}
+	for _, pos := range []PartOfSpeech{Noun, Verb, Adjective, Adverb} {
+		ic.add(pos, 0, opts.Smoothing)
+	}
+	if opts.Smoothing > 0 {
+		for i := range h.clusters {
+			c := &h.clusters[i]
+			ic.add(c.pos, c.offset, opts.Smoothing)
+		}
+	}
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Strings(words) // sum in a fixed order
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		senses, _ := h.Lookup(Criteria{Matching: word, Morphology: true})
+		if len(senses) == 0 {
+			continue
+		}
+		weight := counts[word]
+		if !opts.WholeSenses {
+			weight /= float64(len(senses))
+		}
+		for _, s := range senses {
+			h.hypernymLevels(s.cluster, func(c uint32) {
+				ic.add(s.POS(), h.clusters[c].offset, weight)
+			})
+			ic.add(s.POS(), 0, weight)
+		}
+	}
+	return ic
+}
+
+// hypernymLevels calls cb for a cluster and then for each level of its
+// hypernyms, as NLTK's Synset._iter_hypernym_lists lists them.  A
+// hypernym is not listed again once it has been listed at a shallower
+// level, but is listed as many times as it is reached from the level
+// below it.  So when a synset has two hypernyms with a common hypernym,
+// that and all above it are credited twice, but a hypernym reached by
+// paths of different lengths is credited once.
+func (h *Handle) hypernymLevels(c uint32, cb func(uint32)) {
+	seen := map[uint32]bool{}
+	level := []uint32{c}
+	for len(level) > 0 {
+		for _, c := range level {
+			seen[c] = true
+		}
+		var next []uint32
+		for _, c := range level {
+			cb(c)
+			for _, e := range h.edges(&h.clusters[c]) {
+				if e.rel&hypernymRelations != 0 && !seen[e.cluster] {
+					next = append(next, e.cluster)
+				}
+			}
+		}
+		level = next
+	}
+}
+
+// The information content of a synset.  ok is false for parts of speech
+// which have no counts, such as adjectives in ic-brown.dat.  Synsets
+// which never occur have information content 1e300, as in NLTK.
+func (ic *InformationContent) Of(l Lookup) (float64, bool) {
+	counts := ic.counts[l.POS()]
+	if counts == nil {
+		return 0, false
+	}
+	n := counts[l.c().offset]
+	if n == 0 {
+		return infiniteIC, true
+	}
+	return -math.Log(n / counts[0]), true
+}
+
+// icScores returns the information content of two synsets and of their
+// most informative common hypernym, or zero if they have none
+func (h *Handle) icScores(a, b Lookup, ic *InformationContent) (icA, icB, icLCS float64, ok bool) {
+	if ic == nil || a.c().posLetter() != b.c().posLetter() {
+		return 0, 0, 0, false
+	}
+	if icA, ok = ic.Of(a); !ok {
+		return 0, 0, 0, false
+	}
+	icB, _ = ic.Of(b)
+	other := h.ancestors(b.cluster, hypernymRelations)
+	found := false
+	for c := range h.ancestors(a.cluster, hypernymRelations) {
+		if other[c] {
+			if s, _ := ic.Of(h.lookup(c)); !found || s > icLCS {
+				icLCS, found = s, true
+			}
+		}
+	}
+	return icA, icB, icLCS, true
+}
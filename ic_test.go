@@ -0,0 +1,118 @@
+package wnram
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// counts for travel and some of its hyponyms, out of 400
+const testIC = `wnver::test
+1839438v 100 ROOT
+1908923v 40
+1921973v 10
+2059573v 30
+1930264v 20
+1932532v 5
+2000000v 300 ROOT
+`
+
+func TestInformationContent(t *testing.T) {
+	needWordnet(t)
+	fsys := fstest.MapFS{"ic-test.dat": {Data: []byte(testIC)}}
+	ic, err := LoadInformationContent(fsys, "ic-test.dat")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	stroll, _ := wnInstance.Synset("01921973-v")
+	sprint, _ := wnInstance.Synset("01932532-v")
+	opts := SimilarityOptions{IC: ic}
+	for m, want := range map[Measure]float64{
+		ResnikSimilarity: math.Log(4),
+		LinSimilarity:    2 * math.Log(4) / (math.Log(40) + math.Log(80)),
+		JCNSimilarity:    1 / math.Log(200),
+	} {
+		if got, ok := wnInstance.Similarity(stroll, sprint, m, opts); !ok || math.Abs(got-want) > 1e-9 {
+			t.Errorf("expected %s similarity %v, got %v", m, want, got)
+		}
+	}
+	if got, _ := wnInstance.Similarity(stroll, stroll, JCNSimilarity, opts); got != 1e300 {
+		t.Errorf("expected a synset to be identical to itself, got %v", got)
+	}
+	if _, ok := wnInstance.Similarity(stroll, sprint, ResnikSimilarity, SimilarityOptions{}); ok {
+		t.Errorf("expected no similarity without information content")
+	}
+	adjs, _ := wnInstance.Lookup(Criteria{Matching: "good", POS: PartOfSpeechList{Adjective}})
+	if _, ok := ic.Of(adjs[0]); ok {
+		t.Errorf("expected no information content for adjectives")
+	}
+
+	fsys["ic-bad.dat"] = &fstest.MapFile{Data: []byte("wnver::test\n1839438v ten ROOT\n")}
+	_, err = LoadInformationContent(fsys, "ic-bad.dat")
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 2 || pe.Column != 10 || pe.Token != "ten" {
+		t.Errorf("expected an error at line 2, column 10, got %v", err)
+	}
+}
+
+func TestComputeInformationContent(t *testing.T) {
+	needWordnet(t)
+	ic := wnInstance.ComputeInformationContent(map[string]float64{"stroll": 3}, ICOptions{Smoothing: 1, WholeSenses: true})
+	stroll, _ := wnInstance.Synset("01921973-v")
+	sprint, _ := wnInstance.Synset("01932532-v")
+	walk := stroll.Related(Hypernym)[0]
+	// stroll, walk and travel have all 4 counts of verbs, sprint only its
+	// smoothing
+	for _, test := range []struct {
+		l    Lookup
+		want float64
+	}{{stroll, 0}, {walk, 0}, {sprint, math.Log(4)}} {
+		if got, ok := ic.Of(test.l); !ok || math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("expected information content %v for %s, got %v", test.want, test.l.Word(), got)
+		}
+	}
+	if got, _ := wnInstance.Similarity(stroll, sprint, LinSimilarity, SimilarityOptions{IC: ic}); got != 0 {
+		t.Errorf("expected no similarity through travel, got %v", got)
+	}
+}
+
+// dog is a canine and a pet, both animals, and mutt is a dog and an
+// animal
+var icFS = fstest.MapFS{
+	"data.noun": {Data: []byte(
+		"00000010 03 n 01 entity 0 000 | that which exists\n" +
+			"00000020 05 n 01 animal 0 001 @ 00000010 n 0000 | a living organism\n" +
+			"00000030 05 n 01 canine 0 001 @ 00000020 n 0000 | of the family Canidae\n" +
+			"00000040 05 n 01 pet 0 001 @ 00000020 n 0000 | a domesticated animal\n" +
+			"00000050 05 n 01 dog 0 002 @ 00000030 n 0000 @ 00000040 n 0000 | a domesticated canine\n" +
+			"00000060 05 n 01 mutt 0 002 @ 00000050 n 0000 @ 00000020 n 0000 | a dog of mixed breed\n")},
+	"index.noun": {Data: []byte(
+		"dog n 1 1 @ 1 0 00000050  \n" +
+			"mutt n 1 1 @ 1 0 00000060  \n")},
+}
+
+// Counts are added to hypernyms as NLTK adds them: once for each
+// synset of the level below which reaches them, but not again from a
+// deeper level
+func TestComputeInformationContentLevels(t *testing.T) {
+	h, err := NewFS(icFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for _, test := range []struct {
+		word string
+		want map[uint32]float64
+	}{
+		// dog; canine, pet; animal, animal; entity, entity
+		{"dog", map[uint32]float64{0: 1, 10: 2, 20: 2, 30: 1, 40: 1, 50: 1}},
+		// mutt; dog, animal; canine, pet, entity
+		{"mutt", map[uint32]float64{0: 1, 10: 1, 20: 1, 30: 1, 40: 1, 50: 1, 60: 1}},
+	} {
+		ic := h.ComputeInformationContent(map[string]float64{test.word: 1}, ICOptions{})
+		if got := ic.counts[Noun]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected counts %v, got %v", test.word, test.want, got)
+		}
+	}
+}
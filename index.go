@@ -0,0 +1,89 @@
+package wnram
+
+import (
+	"sort"
+	"strings"
+)
+
+// Identifies a lemma within a part of speech, as in the index.* files
+type lemmaKey struct {
+	lemma string
+	pos   PartOfSpeech
+}
+
+// indexPOS identifies the part of speech of an index file from its
+// file name (index.noun, index.verb, index.adj or index.adv)
+func indexPOS(base string) (PartOfSpeech, bool) {
+	if !strings.HasPrefix(base, "index.") {
+		return 0, false
+	}
+	return posFromName(strings.TrimPrefix(base, "index."))
+}
+
+// senseNumber returns the WordNet sense number of the normalized
+// lemma in the cluster, or zero if unknown.
+func (c *cluster) senseNumber(key string) int {
+	for _, w := range c.words {
+		if normalize(w.word) == key {
+			return int(w.senseNumber)
+		}
+	}
+	return 0
+}
+
+// sortIndex orders the clusters of every index entry by part of speech
+// and then by sense number, which is WordNet's frequency order.
+// Clusters without a known sense number sort last, by offset.
+func sortIndex(index map[string][]*cluster) {
+	for key, clusters := range index {
+		sort.SliceStable(clusters, func(i, j int) bool {
+			a, b := clusters[i], clusters[j]
+			if a.pos != b.pos {
+				return a.pos < b.pos
+			}
+			sa, sb := a.senseNumber(key), b.senseNumber(key)
+			if sa != sb {
+				if sa == 0 || sb == 0 {
+					return sb == 0
+				}
+				return sa < sb
+			}
+			return a.offset < b.offset
+		})
+	}
+}
+
+// The sense number of this word within its part of speech.  Sense 1
+// is the most frequently tagged meaning.  Returns zero when the index
+// files were not available at load time.
+func (w *Lookup) SenseNumber() int {
+	if word := w.wordRec(); word != nil {
+		return int(word.senseNumber)
+	}
+	return 0
+}
+
+// The number of senses of lemma which have been tagged in semantic
+// concordance texts, as recorded in the index files.
+func (h *Handle) TagSenseCount(lemma string, pos PartOfSpeech) int {
+	lemma = normalize(lemma)
+	i := sort.Search(len(h.tagCounts), func(i int) bool {
+		r := &h.tagCounts[i]
+		return h.str(r.lemma) > lemma || (h.str(r.lemma) == lemma && PartOfSpeech(r.pos) >= pos)
+	})
+	if i < len(h.tagCounts) && h.str(h.tagCounts[i].lemma) == lemma && PartOfSpeech(h.tagCounts[i].pos) == pos {
+		return int(h.tagCounts[i].count)
+	}
+	return 0
+}
+
+// Find the most frequent sense of a word as the given part of speech.
+// Requires the index files to have been loaded, otherwise an arbitrary
+// sense is returned.
+func (h *Handle) MostFrequentSense(word string, pos PartOfSpeech) (Lookup, bool) {
+	found, err := h.Lookup(Criteria{Matching: word, POS: PartOfSpeechList{pos}})
+	if err != nil || len(found) == 0 {
+		return Lookup{}, false
+	}
+	return found[0], true
+}
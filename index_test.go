@@ -0,0 +1,63 @@
+package wnram
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// Two senses of run, of which index.verb lists the second first
+var indexFS = fstest.MapFS{
+	"data.verb": {Data: []byte(
+		"00000010 38 v 02 run 0 go 0 001 @ 00000030 v 0000 01 + 02 00 | move fast by using one's feet\n" +
+			"00000020 41 v 01 run 1 000 01 + 08 00 | be in charge of; \"who runs this show?\"\n" +
+			"00000030 38 v 01 travel 0 001 ~ 00000010 v 0000 01 + 01 00 | change location\n")},
+	"index.verb": {Data: []byte(
+		"go v 1 1 @ 1 0 00000010  \n" +
+			"run v 2 1 @ 2 1 00000020 00000010  \n" +
+			"travel v 1 1 ~ 1 0 00000030  \n")},
+}
+
+func TestSenseOrder(t *testing.T) {
+	h, err := NewFS(indexFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found, err := h.Lookup(Criteria{Matching: "run", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected two senses of run, got %d", len(found))
+	}
+	for i, f := range found {
+		if f.SenseNumber() != i+1 {
+			t.Errorf("expected sense %d of run, got %d", i+1, f.SenseNumber())
+		}
+	}
+	if found[0].ID() != "00000020-v" {
+		t.Errorf("expected senses in the order of index.verb, got %s first", found[0].ID())
+	}
+	if n := h.TagSenseCount("run", Verb); n != 1 {
+		t.Errorf("expected one tagged sense of run, got %d", n)
+	}
+	if n := h.TagSenseCount("go", Verb); n != 0 {
+		t.Errorf("expected no tagged senses of go, got %d", n)
+	}
+}
+
+func TestMostFrequentSense(t *testing.T) {
+	h, err := NewFS(indexFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	mfs, ok := h.MostFrequentSense("run", Verb)
+	if !ok {
+		t.Fatalf("no senses for run")
+	}
+	if mfs.SenseNumber() != 1 || mfs.Definition() != "be in charge of" {
+		t.Errorf("unexpected most frequent sense of run: %d %s", mfs.SenseNumber(), mfs.Gloss())
+	}
+	if _, ok := h.MostFrequentSense("run", Noun); ok {
+		t.Errorf("expected no noun senses of run")
+	}
+}
@@ -0,0 +1,82 @@
+package wnram
+
+// The names of the lexicographer files, indexed by file number, as in
+// WordNet's lexnames file.  Used when no lexnames file is loaded.
+var defaultLexNames = []string{
+	"adj.all",
+	"adj.pert",
+	"adv.all",
+	"noun.Tops",
+	"noun.act",
+	"noun.animal",
+	"noun.artifact",
+	"noun.attribute",
+	"noun.body",
+	"noun.cognition",
+	"noun.communication",
+	"noun.event",
+	"noun.feeling",
+	"noun.food",
+	"noun.group",
+	"noun.location",
+	"noun.motive",
+	"noun.object",
+	"noun.person",
+	"noun.phenomenon",
+	"noun.plant",
+	"noun.possession",
+	"noun.process",
+	"noun.quantity",
+	"noun.relation",
+	"noun.shape",
+	"noun.state",
+	"noun.substance",
+	"noun.time",
+	"verb.body",
+	"verb.change",
+	"verb.cognition",
+	"verb.communication",
+	"verb.competition",
+	"verb.consumption",
+	"verb.contact",
+	"verb.creation",
+	"verb.emotion",
+	"verb.motion",
+	"verb.perception",
+	"verb.possession",
+	"verb.social",
+	"verb.stative",
+	"verb.weather",
+	"adj.ppl",
+}
+
+// lexName returns the name of a lexicographer file
+func (h *Handle) lexName(n uint8) string {
+	if int(n) < len(h.lexNames) {
+		return h.str(h.lexNames[n])
+	}
+	return ""
+}
+
+// The name of the lexicographer file this meaning was defined in, e.g.
+// "noun.animal" or "verb.motion".  These are the coarse semantic
+// categories often called supersenses.
+func (w *Lookup) LexFile() string {
+	return w.h.lexName(w.c().lexFile)
+}
+
+// Iterate over clusters satisfying the criteria, which are checked
+// against the canonical synonym of each cluster.  Matching is ignored,
+// e.g. Criteria{LexFile: "noun.animal"} visits all animals.
+func (h *Handle) IterateCriteria(crit Criteria, cb func(Lookup) error) error {
+	for i := range h.clusters {
+		l := h.lookup(uint32(i))
+		if !crit.matches(&l) {
+			continue
+		}
+		if err := cb(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
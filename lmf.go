@@ -0,0 +1,622 @@
+package wnram
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The subset of the Global WordNet Association's LMF format (WN-LMF
+// 1.1) which maps onto the WordNet database.  The same types are used
+// for reading and writing.
+
+type lmfRelation struct {
+	RelType string `xml:"relType,attr"`
+	Target  string `xml:"target,attr"`
+}
+
+type lmfLemma struct {
+	WrittenForm  string `xml:"writtenForm,attr"`
+	PartOfSpeech string `xml:"partOfSpeech,attr"`
+}
+
+type lmfForm struct {
+	WrittenForm string `xml:"writtenForm,attr"`
+}
+
+type lmfSense struct {
+	ID          string        `xml:"id,attr"`
+	Synset      string        `xml:"synset,attr"`
+	AdjPosition string        `xml:"adjposition,attr,omitempty"`
+	Subcat      string        `xml:"subcat,attr,omitempty"`
+	Relations   []lmfRelation `xml:"SenseRelation"`
+	Counts      []int         `xml:"Count"`
+}
+
+type lmfBehaviour struct {
+	ID     string `xml:"id,attr,omitempty"`
+	Frame  string `xml:"subcategorizationFrame,attr"`
+	Senses string `xml:"senses,attr,omitempty"`
+}
+
+type lmfEntry struct {
+	ID         string         `xml:"id,attr"`
+	Lemma      lmfLemma       `xml:"Lemma"`
+	Forms      []lmfForm      `xml:"Form"`
+	Senses     []lmfSense     `xml:"Sense"`
+	Behaviours []lmfBehaviour `xml:"SyntacticBehaviour"`
+}
+
+type lmfSynset struct {
+	ID           string        `xml:"id,attr"`
+	ILI          string        `xml:"ili,attr"`
+	PartOfSpeech string        `xml:"partOfSpeech,attr"`
+	Members      string        `xml:"members,attr,omitempty"`
+	LexFile      string        `xml:"lexfile,attr,omitempty"`
+	Definitions  []string      `xml:"Definition"`
+	Examples     []string      `xml:"Example"`
+	Relations    []lmfRelation `xml:"SynsetRelation"`
+}
+
+type lmfLexicon struct {
+	ID       string         `xml:"id,attr"`
+	Label    string         `xml:"label,attr"`
+	Language string         `xml:"language,attr"`
+	Email    string         `xml:"email,attr"`
+	License  string         `xml:"license,attr"`
+	Version  string         `xml:"version,attr"`
+	URL      string         `xml:"url,attr,omitempty"`
+	Entries  []lmfEntry     `xml:"LexicalEntry"`
+	Synsets  []lmfSynset    `xml:"Synset"`
+	Frames   []lmfBehaviour `xml:"SyntacticBehaviour"`
+}
+
+type lmfResource struct {
+	XMLName xml.Name   `xml:"LexicalResource"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Lexicon lmfLexicon `xml:"Lexicon"`
+}
+
+// The relType names used in LMF for each relation.  VerbGroup is
+// called "similar", like SimilarTo, and is told apart by part of
+// speech.  Members of a domain point to it by ";c", ";r" and ";u" in
+// the database files, as by domain_topic, domain_region and exemplifies
+// in LMF.
+var lmfRelTypes = map[Relation]string{
+	AlsoSee:                   "also",
+	Antonym:                   "antonym",
+	Attribute:                 "attribute",
+	Cause:                     "causes",
+	DerivationallyRelatedForm: "derivation",
+	Pertainym:                 "pertainym",
+	ContainsDomainRegion:      "domain_region",
+	ContainsDomainTopic:       "domain_topic",
+	ContainsDomainUsage:       "exemplifies",
+	InDomainRegion:            "has_domain_region",
+	InDomainTopic:             "has_domain_topic",
+	InDomainUsage:             "is_exemplified_by",
+	Entailment:                "entails",
+	Hypernym:                  "hypernym",
+	InstanceHypernym:          "instance_hypernym",
+	InstanceHyponym:           "instance_hyponym",
+	Hyponym:                   "hyponym",
+	MemberMeronym:             "mero_member",
+	PartMeronym:               "mero_part",
+	SubstanceMeronym:          "mero_substance",
+	MemberHolonym:             "holo_member",
+	PartHolonym:               "holo_part",
+	SubstanceHolonym:          "holo_substance",
+	ParticipleOfVerb:          "participle",
+	SimilarTo:                 "similar",
+	VerbGroup:                 "similar",
+}
+
+// lmfRelationType maps an LMF relType to a relation.  Relation types
+// without a WordNet equivalent are reported as not found.
+func lmfRelationType(relType string, pos PartOfSpeech) (Relation, bool) {
+	if relType == "similar" && pos == Verb {
+		return VerbGroup, true
+	}
+	for rel, name := range lmfRelTypes {
+		if name == relType && rel != VerbGroup {
+			return rel, true
+		}
+	}
+	return 0, false
+}
+
+var lmfMarkers = map[string]SyntacticMarker{
+	"a":  Attributive,
+	"p":  Predicative,
+	"ip": ImmediatelyPostnominal,
+}
+
+// lmfPOS maps an LMF part of speech, which distinguishes satellites
+func lmfPOS(letter string) (PartOfSpeech, bool, error) {
+	if len(letter) != 1 {
+		return 0, false, fmt.Errorf("unsupported part of speech: %q", letter)
+	}
+	l := lexable(letter)
+	pos, err := l.lexPOS()
+	return pos, letter == "s", err
+}
+
+var lmfOffsetID = regexp.MustCompile(`(\d{8})-[nvasr]$`)
+
+// lmfLexID extracts the lex_id from a sense identifier which embeds a
+// sense key, as the Open English WordNet does ("oewn-dog__1.05.00..").
+func lmfLexID(id string) uint8 {
+	i := strings.Index(id, "__")
+	if i < 0 {
+		return 0
+	}
+	fields := strings.Split(id[i+2:], ".")
+	if len(fields) < 3 {
+		return 0
+	}
+	n, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0
+	}
+	return uint8(n)
+}
+
+// Initialize a new in-ram WordNet database from a Global WordNet
+// Association LMF file (WN-LMF XML), such as the Open English WordNet.
+// Only the first lexicon in the file is read.
+func NewFromLMF(filename string) (*Handle, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ld := newLoader()
+	sum := sha256.New()
+	if err := ld.loadLMF(io.TeeReader(f, sum)); err != nil {
+		e := &ParseError{File: filename, Err: err}
+		var se *xml.SyntaxError
+		if errors.As(err, &se) {
+			e.Line = int64(se.Line)
+		}
+		return nil, e
+	}
+	// include any lexicons after the first
+	if _, err := io.Copy(sum, f); err != nil {
+		return nil, err
+	}
+	ld.sources = append(ld.sources, source{filepath.Base(filename), sum.Sum(nil)})
+	return ld.handle()
+}
+
+type lmfSenseRef struct {
+	entry *lmfEntry
+	sense *lmfSense
+	// position of the sense within its entry
+	number int
+}
+
+// loadLMF reads a lexicon from an LMF document
+func (ld *loader) loadLMF(r io.Reader) error {
+	var entries []lmfEntry
+	var synsets []lmfSynset
+	var frames []lmfBehaviour
+	dec := xml.NewDecoder(r)
+	lexicons := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "Lexicon":
+			if lexicons++; lexicons > 1 {
+				dec.Skip()
+			}
+		case "LexicalEntry":
+			var e lmfEntry
+			if err := dec.DecodeElement(&e, &start); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+		case "Synset":
+			var s lmfSynset
+			if err := dec.DecodeElement(&s, &start); err != nil {
+				return err
+			}
+			synsets = append(synsets, s)
+		case "SyntacticBehaviour":
+			var b lmfBehaviour
+			if err := dec.DecodeElement(&b, &start); err != nil {
+				return err
+			}
+			frames = append(frames, b)
+		}
+	}
+
+	// synsets are identified by offset where the ids allow it, and the
+	// others are numbered after the greatest offset given
+	offsets := make([]string, len(synsets))
+	next := 1
+	for i, s := range synsets {
+		if m := lmfOffsetID.FindStringSubmatch(s.ID); m != nil {
+			offsets[i] = m[1]
+			if n, _ := strconv.Atoi(m[1]); n >= next {
+				next = n + 1
+			}
+		}
+	}
+	keys := map[string]offsetKey{}
+	for i, s := range synsets {
+		pos, satellite, err := lmfPOS(s.PartOfSpeech)
+		if err != nil {
+			return fmt.Errorf("synset %s: %s", s.ID, err)
+		}
+		offset := offsets[i]
+		if offset == "" {
+			offset = fmt.Sprintf("%08d", next)
+			next++
+		}
+		key := offsetKey{offset, pos}
+		if _, ok := ld.byOffset[key]; ok {
+			return fmt.Errorf("synset %s: duplicate offset %s", s.ID, offset)
+		}
+		keys[s.ID] = key
+		c := ld.cluster(key)
+		c.pos = pos
+		c.satellite = satellite
+		c.offset = offset
+		c.ili = s.ILI
+		c.gloss = strings.Join(s.Definitions, "; ")
+		for _, e := range s.Examples {
+			c.gloss += "; \"" + e + "\""
+		}
+		for n, name := range ld.lexNames {
+			if name == s.LexFile {
+				c.lexFile = uint8(n)
+			}
+		}
+	}
+
+	// words are ordered as the synset's members, or as read
+	bySynset := map[string][]lmfSenseRef{}
+	for i := range entries {
+		e := &entries[i]
+		for j := range e.Senses {
+			s := &e.Senses[j]
+			bySynset[s.Synset] = append(bySynset[s.Synset], lmfSenseRef{e, s, j + 1})
+		}
+	}
+	senses := map[string]senseRef{}
+	for _, s := range synsets {
+		refs := bySynset[s.ID]
+		members := strings.Fields(s.Members)
+		member := func(id string) int {
+			for i, m := range members {
+				if m == id {
+					return i
+				}
+			}
+			return len(members)
+		}
+		sort.SliceStable(refs, func(i, j int) bool {
+			return member(refs[i].entry.ID) < member(refs[j].entry.ID)
+		})
+		c := ld.byOffset[keys[s.ID]]
+		for _, ref := range refs {
+			w := word{
+				word:        ref.entry.Lemma.WrittenForm,
+				sense:       lmfLexID(ref.sense.ID),
+				marker:      lmfMarkers[ref.sense.AdjPosition],
+				senseNumber: uint16(ref.number),
+			}
+			for _, n := range ref.sense.Counts {
+				w.count += uint32(n)
+			}
+			senses[ref.sense.ID] = senseRef{c, len(c.words)}
+			c.words = append(c.words, w)
+		}
+	}
+	for id := range bySynset {
+		if _, ok := keys[id]; !ok {
+			return fmt.Errorf("sense refers to %w %s", ErrUnknownSynset, id)
+		}
+	}
+
+	// inflected forms, relations and verb frames
+	frameNumbers := map[string]uint8{}
+	for n, f := range verbFrames {
+		frameNumbers[f] = uint8(n)
+	}
+	addFrame := func(frame, senseIDs string) {
+		n, ok := frameNumbers[frame]
+		if !ok || n == 0 {
+			return
+		}
+		for _, id := range strings.Fields(senseIDs) {
+			if ref, ok := senses[id]; ok {
+				w := &ref.cluster.words[ref.word]
+				w.frames = append(w.frames, n)
+			}
+		}
+	}
+	subcats := map[string]string{}
+	for _, f := range frames {
+		if f.Senses != "" {
+			addFrame(f.Frame, f.Senses)
+		} else {
+			subcats[f.ID] = f.Frame
+		}
+	}
+	for _, e := range entries {
+		pos, _, err := lmfPOS(e.Lemma.PartOfSpeech)
+		if err != nil {
+			return fmt.Errorf("entry %s: %s", e.ID, err)
+		}
+		for _, f := range e.Forms {
+			if ld.excs[pos] == nil {
+				ld.excs[pos] = exceptions{}
+			}
+			form := normalize(f.WrittenForm)
+			ld.excs[pos][form] = append(ld.excs[pos][form], normalize(e.Lemma.WrittenForm))
+		}
+		var ids []string
+		for _, s := range e.Senses {
+			ids = append(ids, s.ID)
+			for _, sc := range strings.Fields(s.Subcat) {
+				addFrame(subcats[sc], s.ID)
+			}
+			ref := senses[s.ID]
+			for _, r := range s.Relations {
+				rel, ok := lmfRelationType(r.RelType, pos)
+				if !ok {
+					continue
+				}
+				target, ok := senses[r.Target]
+				if !ok {
+					return fmt.Errorf("sense %s: %w %s", s.ID, ErrDanglingRelation, r.Target)
+				}
+				w := &ref.cluster.words[ref.word]
+				w.relations = append(w.relations, syntacticRelation{
+					rel:        rel,
+					target:     target.cluster,
+					wordNumber: uint8(target.word),
+				})
+			}
+		}
+		for _, b := range e.Behaviours {
+			if b.Senses == "" {
+				addFrame(b.Frame, strings.Join(ids, " "))
+			} else {
+				addFrame(b.Frame, b.Senses)
+			}
+		}
+	}
+	for _, s := range synsets {
+		c := ld.byOffset[keys[s.ID]]
+		for _, r := range s.Relations {
+			rel, ok := lmfRelationType(r.RelType, c.pos)
+			if !ok {
+				continue
+			}
+			target, ok := keys[r.Target]
+			if !ok {
+				return fmt.Errorf("synset %s: %w %s", s.ID, ErrDanglingRelation, r.Target)
+			}
+			c.relations = append(c.relations, semanticRelation{
+				rel:    rel,
+				target: ld.byOffset[target],
+			})
+		}
+	}
+	return nil
+}
+
+// Identifies the LMF lexicon written by Handle.WriteLMF
+type LMFLexicon struct {
+	// A short identifier prefixed to all ids in the lexicon, e.g. "wn"
+	ID       string
+	Label    string
+	Language string
+	Email    string
+	License  string
+	Version  string
+	URL      string
+}
+
+// Characters escaped in LMF identifiers, as in the Open English WordNet
+var lmfEscapes = map[rune]string{
+	'\'': "-ap-",
+	'!':  "-ex-",
+	',':  "-cm-",
+	':':  "-cn-",
+	'+':  "-pl-",
+	'/':  "-sl-",
+	'(':  "-lb-",
+	')':  "-rb-",
+}
+
+// lmfEscape makes a lemma usable in an XML identifier
+func lmfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == ' ':
+			b.WriteByte('_')
+		case r == '-' || r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case lmfEscapes[r] != "":
+			b.WriteString(lmfEscapes[r])
+		default:
+			fmt.Fprintf(&b, "-u%04x-", r)
+		}
+	}
+	return b.String()
+}
+
+// WriteLMF writes the database as a WN-LMF 1.1 document.  Relations
+// which WN-LMF has no relType for, such as RelatedForm, are left out.
+func (h *Handle) WriteLMF(w io.Writer, lex LMFLexicon) error {
+	synsetID := func(c uint32) string {
+		r := &h.clusters[c]
+		return fmt.Sprintf("%s-%08d-%c", lex.ID, r.offset, r.posLetter())
+	}
+	senseID := func(word *wordRec) string {
+		key := h.senseKey(word)
+		j := strings.IndexByte(key, '%')
+		return lex.ID + "-" + lmfEscape(h.str(word.word)) + "__" + strings.Replace(key[j+1:], ":", ".", -1)
+	}
+
+	// inflected forms of each lemma
+	forms := map[lemmaKey][]string{}
+	for _, exc := range h.exceptions {
+		inflected := h.str(exc.key)
+		for _, b := range h.strs(exc.first, exc.n) {
+			k := lemmaKey{b, PartOfSpeech(exc.pos)}
+			forms[k] = append(forms[k], inflected)
+		}
+	}
+
+	// group senses into entries by lemma and part of speech
+	entryIndex := map[string]int{}
+	var entries []lmfEntry
+	var entrySenses [][]*wordRec
+	synsets := make([]lmfSynset, 0, len(h.clusters))
+	for ci := range h.clusters {
+		c := &h.clusters[ci]
+		s := lmfSynset{
+			ID:           synsetID(uint32(ci)),
+			ILI:          h.str(c.ili),
+			PartOfSpeech: string(c.posLetter()),
+			LexFile:      h.lexName(c.lexFile),
+		}
+		l := h.lookup(uint32(ci))
+		if def := l.Definition(); def != "" {
+			s.Definitions = []string{def}
+		}
+		s.Examples = l.Examples()
+		var members []string
+		words := h.words(c)
+		for i := range words {
+			word := h.str(words[i].word)
+			id := fmt.Sprintf("%s-%s-%c", lex.ID, lmfEscape(word), c.posLetter())
+			n, ok := entryIndex[id]
+			if !ok {
+				n = len(entries)
+				entryIndex[id] = n
+				e := lmfEntry{
+					ID:    id,
+					Lemma: lmfLemma{word, string(c.posLetter())},
+				}
+				sort.Strings(forms[lemmaKey{normalize(word), c.pos}])
+				for _, f := range forms[lemmaKey{normalize(word), c.pos}] {
+					e.Forms = append(e.Forms, lmfForm{f})
+				}
+				entries = append(entries, e)
+				entrySenses = append(entrySenses, nil)
+			}
+			entrySenses[n] = append(entrySenses[n], &words[i])
+			members = append(members, id)
+		}
+		s.Members = strings.Join(members, " ")
+		for _, r := range h.edges(c) {
+			if relType, ok := lmfRelTypes[r.rel]; ok {
+				s.Relations = append(s.Relations, lmfRelation{relType, synsetID(r.cluster)})
+			}
+		}
+		synsets = append(synsets, s)
+	}
+	for n := range entries {
+		es := entrySenses[n]
+		sort.SliceStable(es, func(i, j int) bool {
+			a, b := es[i].senseNumber, es[j].senseNumber
+			return a != 0 && (b == 0 || a < b)
+		})
+		frames := map[uint8][]string{}
+		var frameOrder []uint8
+		for _, word := range es {
+			c := &h.clusters[word.cluster]
+			sense := lmfSense{
+				ID:     senseID(word),
+				Synset: synsetID(word.cluster),
+			}
+			for m, marker := range lmfMarkers {
+				if marker == word.marker {
+					sense.AdjPosition = m
+				}
+			}
+			for _, r := range h.wordEdges(word) {
+				relType, ok := lmfRelTypes[r.rel]
+				if !ok {
+					continue
+				}
+				target := &h.wordRecs[h.clusters[r.cluster].words+r.word]
+				sense.Relations = append(sense.Relations, lmfRelation{relType, senseID(target)})
+			}
+			if word.count > 0 {
+				sense.Counts = []int{int(word.count)}
+			}
+			wordFrames := h.frames[word.frames : word.frames+uint32(word.nFrames)]
+			for _, f := range append(append([]uint8{}, h.frames[c.frames:c.frames+uint32(c.nFrames)]...), wordFrames...) {
+				if _, ok := frames[f]; !ok {
+					frameOrder = append(frameOrder, f)
+				}
+				frames[f] = append(frames[f], sense.ID)
+			}
+			entries[n].Senses = append(entries[n].Senses, sense)
+		}
+		sort.Slice(frameOrder, func(i, j int) bool { return frameOrder[i] < frameOrder[j] })
+		for _, f := range frameOrder {
+			entries[n].Behaviours = append(entries[n].Behaviours, lmfBehaviour{
+				Frame:  VerbFrame(int(f)),
+				Senses: strings.Join(frames[f], " "),
+			})
+		}
+	}
+
+	doc := lmfResource{
+		DC: "https://globalwordnet.github.io/schemas/dc/",
+		Lexicon: lmfLexicon{
+			ID:       lex.ID,
+			Label:    lex.Label,
+			Language: lex.Language,
+			Email:    lex.Email,
+			License:  lex.License,
+			Version:  lex.Version,
+			URL:      lex.URL,
+			Entries:  entries,
+			Synsets:  synsets,
+		},
+	}
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE LexicalResource SYSTEM \"http://globalwordnet.github.io/schemas/WN-LMF-1.1.dtd\">\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// The Interlingual Index identifier of this meaning, e.g. "i46360",
+// which links wordnets in different languages.  Only available when
+// loaded from LMF.
+func (w *Lookup) ILI() string {
+	return w.h.str(w.c().ili)
+}
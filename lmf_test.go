@@ -0,0 +1,277 @@
+package wnram
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLMF = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE LexicalResource SYSTEM "http://globalwordnet.github.io/schemas/WN-LMF-1.1.dtd">
+<LexicalResource xmlns:dc="https://globalwordnet.github.io/schemas/dc/">
+  <Lexicon id="test" label="Test" language="en" email="" license="" version="1">
+    <LexicalEntry id="test-puppy-n">
+      <Lemma writtenForm="puppy" partOfSpeech="n"/>
+      <Form writtenForm="puppies"/>
+      <Sense id="test-puppy__1.05.00.." synset="test-00000002-n">
+        <Count>3</Count>
+      </Sense>
+    </LexicalEntry>
+    <LexicalEntry id="test-dog-n">
+      <Lemma writtenForm="dog" partOfSpeech="n"/>
+      <Sense id="test-dog__1.05.00.." synset="test-00000001-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-bark-v">
+      <Lemma writtenForm="bark" partOfSpeech="v"/>
+      <Sense id="test-bark__2.32.00.." synset="test-00000003-v"/>
+      <SyntacticBehaviour subcategorizationFrame="Something ----s"/>
+    </LexicalEntry>
+    <Synset id="test-00000001-n" ili="i46360" partOfSpeech="n" members="test-dog-n" lexfile="noun.animal">
+      <Definition>a domesticated canid</Definition>
+      <Example>the dog barked all night</Example>
+      <SynsetRelation relType="hyponym" target="test-00000002-n"/>
+    </Synset>
+    <Synset id="test-00000002-n" ili="" partOfSpeech="n" lexfile="noun.animal">
+      <Definition>a young dog</Definition>
+      <SynsetRelation relType="hypernym" target="test-00000001-n"/>
+    </Synset>
+    <Synset id="test-00000003-v" ili="" partOfSpeech="v" lexfile="verb.communication">
+      <Definition>make barking sounds</Definition>
+    </Synset>
+  </Lexicon>
+</LexicalResource>
+`
+
+func TestNewFromLMF(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.xml")
+	if err := os.WriteFile(filename, []byte(testLMF), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewFromLMF(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found, err := h.Lookup(Criteria{Matching: "puppies", Morphology: true})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 || found[0].Word() != "puppy" || found[0].Count() != 3 {
+		t.Fatalf("expected to find puppy from its inflected form")
+	}
+	hypernyms := found[0].Related(Hypernym)
+	if len(hypernyms) != 1 || hypernyms[0].Word() != "dog" {
+		t.Fatalf("expected dog as hypernym of puppy, got %v", hypernyms)
+	}
+	dog := hypernyms[0]
+	if dog.ID() != "00000001-n" || dog.ILI() != "i46360" || dog.LexFile() != "noun.animal" {
+		t.Errorf("unexpected synset for dog: %s %s %s", dog.ID(), dog.ILI(), dog.LexFile())
+	}
+	if dog.Definition() != "a domesticated canid" || len(dog.Examples()) != 1 {
+		t.Errorf("unexpected gloss for dog: %q", dog.Gloss())
+	}
+	if dog.SenseKey() != "dog%1:05:00::" {
+		t.Errorf("unexpected sense key for dog: %s", dog.SenseKey())
+	}
+	bark, _ := h.Lookup(Criteria{Matching: "bark"})
+	if len(bark) != 1 || !setContains(bark[0].VerbFrames(), []string{"Something barks"}) {
+		t.Errorf("expected verb frames for bark")
+	}
+}
+
+// Domains as the Open English WordNet gives them, from the members to
+// the domain and back
+const testLMFDomains = `<?xml version="1.0" encoding="UTF-8"?>
+<LexicalResource xmlns:dc="https://globalwordnet.github.io/schemas/dc/">
+  <Lexicon id="test" label="Test" language="en" email="" license="" version="1">
+    <LexicalEntry id="test-law-n">
+      <Lemma writtenForm="law" partOfSpeech="n"/>
+      <Sense id="test-law__1.14.00.." synset="test-00000001-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-bail-n">
+      <Lemma writtenForm="bail" partOfSpeech="n"/>
+      <Sense id="test-bail__1.21.00.." synset="test-00000002-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-britain-n">
+      <Lemma writtenForm="Britain" partOfSpeech="n"/>
+      <Sense id="test-britain__1.15.00.." synset="test-00000003-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-slang-n">
+      <Lemma writtenForm="slang" partOfSpeech="n"/>
+      <Sense id="test-slang__1.10.00.." synset="test-00000004-n"/>
+    </LexicalEntry>
+    <Synset id="test-00000001-n" partOfSpeech="n" lexfile="noun.act">
+      <Definition>the collection of rules imposed by authority</Definition>
+      <SynsetRelation relType="has_domain_topic" target="test-00000002-n"/>
+    </Synset>
+    <Synset id="test-00000002-n" partOfSpeech="n" lexfile="noun.possession">
+      <Definition>money that must be forfeited if the defendant fails to appear</Definition>
+      <SynsetRelation relType="domain_topic" target="test-00000001-n"/>
+      <SynsetRelation relType="domain_region" target="test-00000003-n"/>
+      <SynsetRelation relType="exemplifies" target="test-00000004-n"/>
+    </Synset>
+    <Synset id="test-00000003-n" partOfSpeech="n" lexfile="noun.location">
+      <Definition>a monarchy in northwestern Europe</Definition>
+      <SynsetRelation relType="has_domain_region" target="test-00000002-n"/>
+    </Synset>
+    <Synset id="test-00000004-n" partOfSpeech="n" lexfile="noun.communication">
+      <Definition>informal language</Definition>
+      <SynsetRelation relType="is_exemplified_by" target="test-00000002-n"/>
+    </Synset>
+  </Lexicon>
+</LexicalResource>
+`
+
+func TestLMFDomains(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.xml")
+	if err := os.WriteFile(filename, []byte(testLMFDomains), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewFromLMF(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	bail, err := h.Synset("00000002-n")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for rel, want := range map[Relation]string{ContainsDomainTopic: "law", ContainsDomainRegion: "Britain", ContainsDomainUsage: "slang"} {
+		if got := bail.Related(rel); len(got) != 1 || got[0].Word() != want {
+			t.Errorf("expected %s as the domain of bail by %v, got %v", want, rel, got)
+		}
+	}
+	for id, rel := range map[string]Relation{"00000001-n": InDomainTopic, "00000003-n": InDomainRegion, "00000004-n": InDomainUsage} {
+		domain, err := h.Synset(id)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if got := domain.Related(rel); len(got) != 1 || got[0].Word() != "bail" {
+			t.Errorf("expected bail as a member of %s by %v, got %v", domain.Word(), rel, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := h.WriteLMF(&buf, LMFLexicon{ID: "test", Label: "Test", Language: "en"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !strings.Contains(buf.String(), `<SynsetRelation relType="domain_topic" target="test-00000001-n"`) {
+		t.Errorf("expected bail to point to its domain:\n%s", buf.String())
+	}
+}
+
+// Synsets whose ids give no offset are numbered after those which do
+func TestLMFOffsets(t *testing.T) {
+	const lmf = `<?xml version="1.0" encoding="UTF-8"?>
+<LexicalResource xmlns:dc="https://globalwordnet.github.io/schemas/dc/">
+  <Lexicon id="test" label="Test" language="en" email="" license="" version="1">
+    <LexicalEntry id="test-dog-n">
+      <Lemma writtenForm="dog" partOfSpeech="n"/>
+      <Sense id="test-dog-n-1" synset="test-00000002-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-cat-n">
+      <Lemma writtenForm="cat" partOfSpeech="n"/>
+      <Sense id="test-cat-n-1" synset="test-cat-n"/>
+    </LexicalEntry>
+    <Synset id="test-00000002-n" partOfSpeech="n">
+      <Definition>a domesticated canid</Definition>
+    </Synset>
+    <Synset id="test-cat-n" partOfSpeech="n">
+      <Definition>a domesticated felid</Definition>
+    </Synset>
+  </Lexicon>
+</LexicalResource>
+`
+	filename := filepath.Join(t.TempDir(), "test.xml")
+	if err := os.WriteFile(filename, []byte(lmf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewFromLMF(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for word, id := range map[string]string{"dog": "00000002-n", "cat": "00000003-n"} {
+		found, err := h.Lookup(Criteria{Matching: word})
+		if err != nil || len(found) != 1 {
+			t.Fatalf("expected to find %s: %v", word, err)
+		}
+		if found[0].ID() != id {
+			t.Errorf("expected %s to be %s, got %s", word, id, found[0].ID())
+		}
+	}
+}
+
+// Relations to unknown senses and synsets are both errors
+func TestLMFDanglingRelations(t *testing.T) {
+	for _, c := range []struct{ from, to string }{
+		{`<Sense id="test-dog__1.05.00.." synset="test-00000001-n"/>`,
+			`<Sense id="test-dog__1.05.00.." synset="test-00000001-n"><SenseRelation relType="antonym" target="test-cat__1.05.00.."/></Sense>`},
+		{`<SynsetRelation relType="hyponym" target="test-00000002-n"/>`,
+			`<SynsetRelation relType="hyponym" target="test-00000009-n"/>`},
+	} {
+		filename := filepath.Join(t.TempDir(), "test.xml")
+		if err := os.WriteFile(filename, []byte(strings.Replace(testLMF, c.from, c.to, 1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewFromLMF(filename); !errors.Is(err, ErrDanglingRelation) {
+			t.Errorf("expected a dangling relation, got %v", err)
+		}
+	}
+}
+
+// Relations without a relType are not written
+func TestWriteLMFUnmapped(t *testing.T) {
+	h, err := NewFS(taxonomyFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for i := range h.edgeRecs {
+		h.edgeRecs[i].rel = RelatedForm
+	}
+	var buf bytes.Buffer
+	if err := h.WriteLMF(&buf, LMFLexicon{ID: "test", Label: "Test", Language: "en"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if strings.Contains(buf.String(), "SynsetRelation") {
+		t.Errorf("expected no relations to be written:\n%s", buf.String())
+	}
+}
+
+func TestLMFRoundTrip(t *testing.T) {
+	needWordnet(t)
+	var buf bytes.Buffer
+	if err := wnInstance.WriteLMF(&buf, LMFLexicon{ID: "wn", Label: "WordNet", Language: "en"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !strings.Contains(buf.String(), `<Lemma writtenForm="yummy" partOfSpeech="s">`) {
+		t.Errorf("expected an entry for yummy")
+	}
+	filename := filepath.Join(t.TempDir(), "wn.xml")
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewFromLMF(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(h.clusters) != len(wnInstance.clusters) {
+		t.Errorf("expected %d synsets, got %d", len(wnInstance.clusters), len(h.clusters))
+	}
+	wnInstance.Iterate(nil, func(want Lookup) error {
+		got, err := h.Synset(want.ID())
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if got.Definition() != want.Definition() || !setContains(got.Examples(), want.Examples()) || got.SenseKey() != want.SenseKey() || got.LexFile() != want.LexFile() {
+			t.Fatalf("synset %s differs after round trip:\n%s\n%s", want.ID(), got.DumpStr(), want.DumpStr())
+		}
+		if len(got.Related(^Relation(0))) != len(want.Related(^Relation(0))) {
+			t.Fatalf("relations of %s differ after round trip", want.ID())
+		}
+		if !setContains(got.VerbFrames(), want.VerbFrames()) {
+			t.Fatalf("verb frames of %s differ after round trip", want.ID())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,882 @@
+package wnram
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// While loading, the database is a graph of clusters, which is then
+// flattened into the Handle's tables.
+
+type syntacticRelation struct {
+	rel        Relation
+	target     *cluster
+	wordNumber uint8
+}
+
+type semanticRelation struct {
+	rel    Relation
+	target *cluster
+}
+
+type word struct {
+	sense       uint8
+	marker      SyntacticMarker
+	senseNumber uint16
+	count       uint32
+	word        string
+	relations   []syntacticRelation
+	frames      []uint8
+	sentences   []uint16
+}
+
+type cluster struct {
+	pos       PartOfSpeech
+	satellite bool
+	lexFile   uint8
+	words     []word
+	gloss     string
+	relations []semanticRelation
+	frames    []uint8
+	offset    string
+	ili       string
+}
+
+// Identifies a synset by its byte offset in the data file for its part
+// of speech
+type offsetKey struct {
+	offset string
+	pos    PartOfSpeech
+}
+
+type indexEntry struct {
+	*parsedIndex
+	filename string
+}
+
+// A loader accumulates the contents of WordNet files, which may be
+// read in any order, and then builds a Handle from them.
+type loader struct {
+	byOffset      map[offsetKey]*cluster
+	excs          map[PartOfSpeech]exceptions
+	indexEntries  []indexEntry
+	senseEntries  []*parsedSense
+	senseIndex    string
+	sentenceIndex map[string][]int
+	verbSentences map[int]string
+	lexNames      []string
+	counts        map[string]int64
+	translations  []*parsedTranslation
+	sources       []source
+	// only compute the checksum of the files, see SourceChecksum
+	checksumOnly bool
+	// collect errors in errs rather than failing, see Options.Lenient
+	lenient bool
+	errs    ErrorList
+	// where relations to clusters not yet read were found, to report
+	// those which are never read
+	referrers map[offsetKey]*ParseError
+	// see Options
+	logger   *slog.Logger
+	progress func(Progress)
+	ctx      context.Context
+	pos      PartOfSpeechList
+	// lines read of the current file
+	lines int64
+
+	// data and index files are parsed by workers in chunks of about
+	// chunkSize bytes, and applied in the order they were read
+	workers   int
+	chunkSize int
+	chunks    []*chunk
+	jobs      chan *chunk
+	wg        sync.WaitGroup
+}
+
+// The checksum of a file's contents
+type source struct {
+	name string
+	sum  []byte
+}
+
+func newLoader() *loader {
+	return &loader{
+		byOffset:      map[offsetKey]*cluster{},
+		excs:          map[PartOfSpeech]exceptions{},
+		sentenceIndex: map[string][]int{},
+		verbSentences: map[int]string{},
+		lexNames:      defaultLexNames,
+		counts:        map[string]int64{},
+		referrers:     map[offsetKey]*ParseError{},
+		ctx:           context.Background(),
+		workers:       runtime.GOMAXPROCS(0),
+		chunkSize:     1 << 20,
+	}
+}
+
+// cluster finds or creates the cluster with the given offset, which may
+// be referenced before it is read.
+func (ld *loader) cluster(key offsetKey) *cluster {
+	c, ok := ld.byOffset[key]
+	if !ok {
+		c = &cluster{}
+		ld.byOffset[key] = c
+	}
+	return c
+}
+
+// fail reports an error, unless loading leniently in which case it is
+// collected and loading continues
+func (ld *loader) fail(err *ParseError) error {
+	if ld.lenient {
+		ld.errs = append(ld.errs, err)
+		if ld.logger != nil {
+			ld.logger.Warn("skipped", "error", err)
+		}
+		return nil
+	}
+	return err
+}
+
+// loadsPOS reports whether a part of speech is loaded, see Options.POS.
+// Satellites are read with the adjectives they refer to, from the
+// adjective files.
+func (ld *loader) loadsPOS(pos PartOfSpeech) bool {
+	return ld.pos.Empty() || ld.pos.Contains(pos) ||
+		pos == Adjective && ld.pos.Contains(AdjectiveSatellite)
+}
+
+// report calls the progress callback, if any
+func (ld *loader) report(filename string, bytes int64, done bool) {
+	if ld.progress != nil {
+		ld.progress(Progress{File: filename, Bytes: bytes, Lines: ld.lines, Done: done})
+	}
+}
+
+// How often progress is reported and cancellation checked, in lines
+const progressLines = 1 << 14
+
+// readLines reads a file with inPlaceReadLine, counting its lines and
+// reporting progress
+func (ld *loader) readLines(filename string, r *countingReader, cb func([]byte, int64, int64) error) error {
+	return inPlaceReadLine(r, func(data []byte, line, offset int64) error {
+		ld.lines = line
+		if line%progressLines == 0 {
+			if err := ld.ctx.Err(); err != nil {
+				return err
+			}
+			ld.report(filename, r.n, false)
+		}
+		return cb(data, line, offset)
+	})
+}
+
+// skipFile reports whether a file should be ignored by name: hidden
+// files and editor backups.
+func skipFile(filename string) bool {
+	return strings.HasPrefix(path.Base(filename), ".") || strings.HasSuffix(filename, "~") || strings.HasSuffix(filename, "#")
+}
+
+// loadAll reads all WordNet files below root in a file system, parsing
+// data and index files in parallel.  The result is the same as reading
+// them one line at a time.
+func (ld *loader) loadAll(fsys fs.FS, root string) error {
+	ld.jobs = make(chan *chunk)
+	for i := 0; i < ld.workers; i++ {
+		ld.wg.Add(1)
+		go func() {
+			defer ld.wg.Done()
+			for c := range ld.jobs {
+				c.parse()
+			}
+		}()
+	}
+	err := ld.loadDir(fsys, root)
+	close(ld.jobs)
+	ld.wg.Wait()
+	ld.jobs = nil
+	// errors in earlier files come first, as when reading sequentially
+	for _, c := range ld.chunks {
+		for _, e := range c.errs {
+			if err := ld.fail(e); err != nil {
+				return err
+			}
+		}
+	}
+	return err
+}
+
+// loadDir reads all WordNet files below root in a file system
+func (ld *loader) loadDir(fsys fs.FS, root string) error {
+	return fs.WalkDir(fsys, root, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := ld.ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip '^.', '~$', and non-files.
+		if skipFile(filename) {
+			return nil
+		}
+		return ld.loadFile(fsys, filename)
+	})
+}
+
+// loadFile reads a file from a file system.  Files compressed with gzip
+// are decompressed, and the contents of zip and tar.gz archives are
+// read as if they were in the directory.
+func (ld *loader) loadFile(fsys fs.FS, filename string) error {
+	base := path.Base(filename)
+	if strings.HasSuffix(base, ".zip") {
+		return ld.loadZip(fsys, filename)
+	}
+	r, name, err := openDecompressed(fsys, filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if strings.HasSuffix(base, ".tar.gz") || strings.HasSuffix(base, ".tgz") {
+		return ld.loadTar(r, filename)
+	}
+	return ld.load(name, r)
+}
+
+// loadZip reads the contents of a zip archive
+func (ld *loader) loadZip(fsys fs.FS, filename string) error {
+	f, err := fsys.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		ra = bytes.NewReader(data)
+	}
+	zr, err := zip.NewReader(ra, info.Size())
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := ld.loadDir(zr, "."); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// loadTar reads the contents of a decompressed tar archive
+func (ld *loader) loadTar(r io.Reader, filename string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || skipFile(hdr.Name) {
+			continue
+		}
+		name := hdr.Name
+		var contents io.Reader = tr
+		if strings.HasSuffix(name, ".gz") {
+			gz, err := gzip.NewReader(tr)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", filename, name, err)
+			}
+			name, contents = strings.TrimSuffix(name, ".gz"), gz
+		}
+		if err := ld.load(name, contents); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+}
+
+// wordnetFile reports whether a file is read when loading, by name
+func wordnetFile(base string) bool {
+	if _, ok := excPOS(base); ok {
+		return true
+	} else if _, ok := omwLanguage(base); ok {
+		return true
+	} else if _, ok := indexPOS(base); ok {
+		return true
+	}
+	switch base {
+	case "index.sense", "sentidx.vrb", "lexnames", "cntlist", "cntlist.rev", "sents.vrb":
+		return true
+	}
+	return strings.HasPrefix(base, "data")
+}
+
+// filePOS returns the part of speech of a data, index or exception
+// file, by name
+func filePOS(base string) (PartOfSpeech, bool) {
+	if pos, ok := excPOS(base); ok {
+		return pos, true
+	} else if pos, ok := indexPOS(base); ok {
+		return pos, true
+	} else if strings.HasPrefix(base, "data.") {
+		return posFromName(strings.TrimPrefix(base, "data."))
+	}
+	return 0, false
+}
+
+// load reads a WordNet file and records a checksum of its contents.
+// Other files, and those of parts of speech not loaded, are ignored.
+func (ld *loader) load(filename string, r io.Reader) error {
+	base := path.Base(filename)
+	if !wordnetFile(base) {
+		return nil
+	} else if pos, ok := filePOS(base); ok && !ld.loadsPOS(pos) {
+		return nil
+	}
+	if err := ld.ctx.Err(); err != nil {
+		return err
+	}
+	start := time.Now()
+	sum := sha256.New()
+	cr := &countingReader{r: io.TeeReader(r, sum)}
+	ld.lines = 0
+	if ld.checksumOnly {
+		if _, err := io.Copy(io.Discard, cr); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	} else if err := ld.parse(filename, cr); err != nil {
+		return err
+	}
+	ld.sources = append(ld.sources, source{base, sum.Sum(nil)})
+	ld.report(filename, cr.n, true)
+	if ld.logger != nil {
+		ld.logger.Info("read file", "file", filename, "bytes", cr.n, "lines", ld.lines, "duration", time.Since(start))
+	}
+	return nil
+}
+
+// parse reads a WordNet file, identifying its contents by name
+func (ld *loader) parse(filename string, r *countingReader) error {
+	// morphological exception lists
+	if pos, ok := excPOS(path.Base(filename)); ok {
+		exc := exceptions{}
+		ld.excs[pos] = exc
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			inflected, bases, err := parseExceptionLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			key := normalize(inflected)
+			for _, b := range bases {
+				exc[key] = append(exc[key], normalize(b))
+			}
+			return nil
+		})
+	}
+	// sense keys, which are validated once all data is read
+	if path.Base(filename) == "index.sense" {
+		ld.senseIndex = filename
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			p, err := parseSenseIndexLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			p.position = position{line, offset}
+			ld.senseEntries = append(ld.senseEntries, p)
+			return nil
+		})
+	}
+	// example sentences for verbs, lexicographer file names and
+	// sense frequencies
+	switch path.Base(filename) {
+	case "sentidx.vrb":
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			key, nums, err := parseSentenceIndexLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			ld.sentenceIndex[key] = nums
+			return nil
+		})
+	case "lexnames":
+		ld.lexNames = nil
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			n, name, err := parseLexnameLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			for len(ld.lexNames) <= n {
+				ld.lexNames = append(ld.lexNames, "")
+			}
+			ld.lexNames[n] = name
+			return nil
+		})
+	case "cntlist", "cntlist.rev":
+		reversed := path.Base(filename) == "cntlist.rev"
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			key, n, err := parseCountLine(data, reversed)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			ld.counts[key] = n
+			return nil
+		})
+	case "sents.vrb":
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			n, sentence, err := parseSentenceLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			}
+			ld.verbSentences[n] = sentence
+			return nil
+		})
+	}
+	// lemmas in other languages, attached once all data is read
+	if lang, ok := omwLanguage(path.Base(filename)); ok {
+		return ld.readLines(filename, r, func(data []byte, line, offset int64) error {
+			p, err := parseTranslationLine(data)
+			if err != nil {
+				return ld.fail(parseError(filename, line, offset, err))
+			} else if p != nil {
+				if p.lang == "" {
+					p.lang = lang
+				}
+				ld.translations = append(ld.translations, p)
+			}
+			return nil
+		})
+	}
+	// lemma indices, which are resolved once all data is read
+	if _, ok := indexPOS(path.Base(filename)); ok {
+		return ld.parseChunks(filename, r, true)
+	}
+	// otherwise read only data files
+	if !strings.HasPrefix(path.Base(filename), "data") {
+		return nil
+	}
+	return ld.parseChunks(filename, r, false)
+}
+
+// A chunk of consecutive lines of a data or index file
+type chunk struct {
+	filename string
+	data     []byte
+	// the number and byte offset of the first line
+	line, offset int64
+	index        bool
+	lenient      bool
+
+	// the results of parsing
+	lines   []*parsed
+	entries []*parsedIndex
+	errs    ErrorList
+}
+
+// parseChunks reads a data or index file in chunks of whole lines,
+// which are parsed by the workers as they are read if loading in
+// parallel, and otherwise immediately, so that parsing overlaps reading
+// and decompression.  The results are applied by applyChunks.
+func (ld *loader) parseChunks(filename string, r io.Reader, index bool) error {
+	br := bufio.NewReader(r)
+	line, offset := int64(1), int64(0)
+	for {
+		data := make([]byte, ld.chunkSize)
+		n, err := io.ReadFull(br, data)
+		data = data[:n]
+		last := err != nil
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		} else if err == nil && data[n-1] != '\n' {
+			// complete the last line
+			var rest []byte
+			rest, err = br.ReadBytes('\n')
+			data = append(data, rest...)
+			if err == io.EOF {
+				last, err = true, nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if !last {
+			_, err := br.Peek(1)
+			last = err == io.EOF
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		c := &chunk{
+			filename: filename,
+			data:     data,
+			line:     line,
+			offset:   offset,
+			index:    index,
+			lenient:  ld.lenient,
+		}
+		ld.chunks = append(ld.chunks, c)
+		line += int64(bytes.Count(data, []byte{'\n'}))
+		offset += int64(len(data))
+		if ld.jobs != nil {
+			ld.jobs <- c
+		} else {
+			c.parse()
+		}
+		if err := ld.ctx.Err(); err != nil {
+			return err
+		}
+		ld.lines = line - 1
+		if last {
+			if data[len(data)-1] != '\n' {
+				ld.lines++
+			}
+			return nil
+		}
+		ld.report(filename, offset, false)
+	}
+}
+
+// parse parses the lines of a chunk, checking all that can be checked
+// without the rest of the database.  Unless lenient, parsing stops at
+// the first error.
+func (c *chunk) parse() {
+	filename := c.filename
+	fail := func(err *ParseError) error {
+		c.errs = append(c.errs, err)
+		if c.lenient {
+			return nil
+		}
+		return err
+	}
+	eachLine(c.data, c.line, c.offset, func(data []byte, line, offset int64) error {
+		if c.index {
+			p, err := parseIndexLine(data)
+			if err != nil {
+				return fail(parseError(filename, line, offset, err))
+			} else if p != nil {
+				p.position = position{line, offset}
+				c.entries = append(c.entries, p)
+			}
+			return nil
+		}
+		p, err := parseLine(data, line, offset)
+		if err != nil {
+			return fail(parseError(filename, line, offset, err))
+		} else if p == nil {
+			return nil
+		}
+		// references to missing words are dropped when lenient
+		frames := p.frames[:0]
+		for _, f := range p.frames {
+			if f.word != 0xff && int(f.word) >= len(p.words) {
+				err := fmt.Errorf("verb frame for word %d of %d: %w", int(f.word)+1, len(p.words), ErrUnknownWord)
+				if err := fail(parseError(filename, line, offset, err)); err != nil {
+					return err
+				}
+				continue
+			}
+			frames = append(frames, f)
+		}
+		p.frames = frames
+		rels := p.rels[:0]
+		for _, r := range p.rels {
+			if !r.isSemantic && int(r.source) >= len(p.words) {
+				err := fmt.Errorf("relation from word %d of %d: %w", int(r.source)+1, len(p.words), ErrUnknownWord)
+				if err := fail(parseError(filename, line, offset, err)); err != nil {
+					return err
+				}
+				continue
+			}
+			rels = append(rels, r)
+		}
+		p.rels = rels
+		c.lines = append(c.lines, p)
+		return nil
+	})
+	c.data = nil
+}
+
+// applyChunks adds the parsed chunks to the database in the order they
+// were read, which is single threaded so that relations may be resolved
+// through byOffset.
+func (ld *loader) applyChunks() {
+	for _, ch := range ld.chunks {
+		for _, p := range ch.entries {
+			ld.indexEntries = append(ld.indexEntries, indexEntry{p, ch.filename})
+		}
+		for _, p := range ch.lines {
+			ld.addCluster(ch.filename, p)
+		}
+	}
+	ld.chunks = nil
+}
+
+// addCluster adds a parsed line of a data file
+func (ld *loader) addCluster(filename string, p *parsed) {
+	// first, let's identify the cluster
+	c := ld.cluster(offsetKey{p.byteOffset, p.pos})
+	// now update
+	c.pos = p.pos
+	c.satellite = p.satellite
+	c.lexFile = uint8(p.fileNum)
+	c.words = p.words
+	c.gloss = p.gloss
+	c.offset = p.byteOffset
+	for _, f := range p.frames {
+		if f.word == 0xff {
+			c.frames = append(c.frames, f.frame)
+		} else {
+			c.words[f.word].frames = append(c.words[f.word].frames, f.frame)
+		}
+	}
+
+	// now let's build relations
+	for _, r := range p.rels {
+		// create the other side of the relationship if needed
+		key := offsetKey{r.offset, r.pos}
+		if _, ok := ld.byOffset[key]; !ok {
+			ld.referrers[key] = &ParseError{
+				File:   filename,
+				Line:   p.line,
+				Offset: p.offset,
+				Token:  r.offset,
+				Err:    fmt.Errorf("%w %s", ErrDanglingRelation, r.offset),
+			}
+		}
+		rcluster := ld.cluster(key)
+		if r.isSemantic {
+			c.relations = append(c.relations, semanticRelation{
+				rel:    r.rel,
+				target: rcluster,
+			})
+		} else {
+			c.words[r.source].relations = append(c.words[r.source].relations, syntacticRelation{
+				rel:        r.rel,
+				target:     rcluster,
+				wordNumber: r.dest,
+			})
+		}
+	}
+}
+
+// checksum combines the checksums of all files read, independent of
+// the order they were read in
+func (ld *loader) checksum() string {
+	sort.Slice(ld.sources, func(i, j int) bool {
+		if ld.sources[i].name != ld.sources[j].name {
+			return ld.sources[i].name < ld.sources[j].name
+		}
+		return bytes.Compare(ld.sources[i].sum, ld.sources[j].sum) < 0
+	})
+	sum := sha256.New()
+	for _, s := range ld.sources {
+		sum.Write([]byte(s.name))
+		sum.Write([]byte{0})
+		sum.Write(s.sum)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// handle builds a Handle once all files have been loaded
+func (ld *loader) handle() (*Handle, error) {
+	ld.applyChunks()
+
+	// clusters which were referred to but never read, and relations to
+	// them when lenient or of parts of speech not loaded
+	var dangling ErrorList
+	removed := false
+	for key, c := range ld.byOffset {
+		if len(c.words) == 0 && !ld.loadsPOS(key.pos) {
+			delete(ld.byOffset, key)
+			removed = true
+		} else if len(c.words) == 0 {
+			err := ld.referrers[key]
+			if err == nil {
+				err = &ParseError{Err: fmt.Errorf("synset %s has no words", key.offset)}
+			}
+			dangling = append(dangling, err)
+			delete(ld.byOffset, key)
+			removed = true
+		}
+	}
+	dangling.sort()
+	for _, err := range dangling {
+		if err := ld.fail(err); err != nil {
+			return nil, err
+		}
+	}
+	if removed {
+		for _, c := range ld.byOffset {
+			relations := c.relations[:0]
+			for _, r := range c.relations {
+				if len(r.target.words) > 0 {
+					relations = append(relations, r)
+				}
+			}
+			c.relations = relations
+			for i := range c.words {
+				w := &c.words[i]
+				relations := w.relations[:0]
+				for _, r := range w.relations {
+					if len(r.target.words) > 0 {
+						relations = append(relations, r)
+					}
+				}
+				w.relations = relations
+			}
+		}
+	}
+
+	// sense numbers are given by the order of synsets in the index
+	tagSenseCounts := map[lemmaKey]int{}
+	for _, e := range ld.indexEntries {
+		key := normalize(e.lemma)
+		for i, offset := range e.offsets {
+			c, ok := ld.byOffset[offsetKey{offset, e.pos}]
+			if !ok {
+				err := ld.fail(&ParseError{
+					File:   e.filename,
+					Line:   e.line,
+					Offset: e.offset,
+					Token:  offset,
+					Err:    fmt.Errorf("%q refers to %w %s", e.lemma, ErrUnknownSynset, offset),
+				})
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			for j := range c.words {
+				if normalize(c.words[j].word) == key {
+					c.words[j].senseNumber = uint16(i + 1)
+				}
+			}
+		}
+		tagSenseCounts[lemmaKey{key, e.pos}] = int(e.tagSenseCount)
+	}
+
+	// now that we've built up the in ram database, lets' index it
+	db := make([]*cluster, 0, len(ld.byOffset))
+	index := make(map[string][]*cluster)
+	for _, c := range ld.byOffset {
+		// add to the global slice of synsets (supports iteration)
+		db = append(db, c)
+
+		// now index all the strings
+		for _, w := range c.words {
+			key := normalize(w.word)
+			v, _ := index[key]
+			v = append(v, c)
+			index[key] = v
+		}
+	}
+	sortIndex(index)
+	sort.Slice(db, func(i, j int) bool {
+		if db[i].pos != db[j].pos {
+			return db[i].pos < db[j].pos
+		}
+		return db[i].offset < db[j].offset
+	})
+
+	// compute sense keys, checking them against index.sense if present
+	senseKeys := make(map[string]senseRef)
+	for _, c := range db {
+		for i := range c.words {
+			senseKeys[c.senseKey(i)] = senseRef{c, i}
+		}
+	}
+	// keys which could not be computed are kept in the Handle
+	extraKeys := make(map[string]senseRef)
+	for _, e := range ld.senseEntries {
+		senseErr := func(err error) error {
+			return ld.fail(&ParseError{File: ld.senseIndex, Line: e.line, Offset: e.offset, Token: e.key, Err: err})
+		}
+		pos, ok := senseKeyPOS(e.key)
+		if !ok {
+			if err := senseErr(fmt.Errorf("malformed sense key %q", e.key)); err != nil {
+				return nil, err
+			}
+			continue
+		} else if !ld.loadsPOS(pos) {
+			continue
+		}
+		if ref, ok := senseKeys[e.key]; ok {
+			if ref.cluster.offset != e.synset || ref.cluster.pos != pos {
+				err := senseErr(fmt.Errorf("%w: %q refers to synset %s, computed from synset %s", ErrSenseKeyMismatch, e.key, e.synset, ref.cluster.offset))
+				if err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		// keep keys we could not derive, if the synset has the lemma
+		c, ok := ld.byOffset[offsetKey{e.synset, pos}]
+		if !ok {
+			if err := senseErr(fmt.Errorf("sense key %q refers to %w %s", e.key, ErrUnknownSynset, e.synset)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		lemma := e.key[:strings.IndexByte(e.key, '%')]
+		for i, w := range c.words {
+			if senseKeyLemma(w.word) == lemma {
+				senseKeys[e.key] = senseRef{c, i}
+				extraKeys[e.key] = senseRef{c, i}
+				break
+			}
+		}
+	}
+
+	// attach frequencies and example sentences, skipping senses no
+	// longer present
+	for key, n := range ld.counts {
+		if ref, ok := senseKeys[key]; ok {
+			ref.cluster.words[ref.word].count = uint32(n)
+		}
+	}
+	for key, nums := range ld.sentenceIndex {
+		if ref, ok := senseKeys[key]; ok {
+			w := &ref.cluster.words[ref.word]
+			for _, n := range nums {
+				w.sentences = append(w.sentences, uint16(n))
+			}
+		}
+	}
+
+	// attach lemmas in other languages, which may refer to synsets of
+	// another release of WordNet
+	languages := map[string]*language{}
+	for _, t := range ld.translations {
+		if c, ok := ld.byOffset[t.synset]; ok {
+			addTranslation(languages, t, c)
+		}
+	}
+
+	b := tableBuilder{h: &Handle{checksum: ld.checksum()}}
+	b.build(db, index, ld.excs, tagSenseCounts, extraKeys, ld.verbSentences, ld.lexNames, languages)
+	if len(ld.errs) > 0 {
+		ld.errs.sort()
+		return b.h, ld.errs
+	}
+	return b.h, nil
+}
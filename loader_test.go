@@ -0,0 +1,101 @@
+package wnram
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Loading in parallel, in chunks of any size, builds the same database
+// as reading the files one line at a time
+func TestParallelLoad(t *testing.T) {
+	needWordnet(t)
+	var want bytes.Buffer
+	if err := wnInstance.WriteSnapshot(&want); err != nil {
+		t.Fatalf("%s", err)
+	}
+	fsys := os.DirFS(sourceCodeRelPath(PathToWordnetDataFiles))
+	for _, c := range []struct{ workers, chunkSize int }{
+		{1, 1 << 30},
+		{4, 4096},
+		{16, 1},
+	} {
+		ld := newLoader()
+		ld.workers, ld.chunkSize, ld.pos = c.workers, c.chunkSize, wnPOS
+		if err := ld.loadAll(fsys, "."); err != nil {
+			t.Fatalf("%d workers: %s", c.workers, err)
+		}
+		h, err := ld.handle()
+		if err != nil {
+			t.Fatalf("%d workers: %s", c.workers, err)
+		}
+		var got bytes.Buffer
+		if err := h.WriteSnapshot(&got); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%d workers, chunks of %d bytes: database differs", c.workers, c.chunkSize)
+		}
+	}
+}
+
+func TestParallelLoadError(t *testing.T) {
+	dir := t.TempDir()
+	noun := "00001740 03 n 01 entity 0 000 | that which exists\n"
+	bad := "00001850 03 n 01 thing 0 001 ! 00001740 n 0901 | broken\n"
+	data := bytes.Repeat([]byte(noun), 1000)
+	data = append(data, bad...)
+	data = append(data, bytes.Repeat([]byte(noun), 1000)...)
+	if err := os.WriteFile(filepath.Join(dir, "data.noun"), data, 0644); err != nil {
+		t.Fatalf("%s", err)
+	}
+	ld := newLoader()
+	ld.chunkSize = 512
+	err := ld.loadAll(os.DirFS(dir), ".")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if want := "data.noun:1001:"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected an error at %s, got %s", want, err)
+	}
+	if !errors.Is(err, ErrUnknownWord) {
+		t.Errorf("expected an unknown word, got %s", err)
+	}
+}
+
+// A reader which fails, checking that lines before it have been parsed
+type parsedReader struct {
+	t  *testing.T
+	ld *loader
+}
+
+func (r parsedReader) Read([]byte) (int, error) {
+	parsed := 0
+	for _, c := range r.ld.chunks {
+		parsed += len(c.lines)
+	}
+	if parsed == 0 {
+		r.t.Errorf("expected lines to be parsed before the file was read")
+	}
+	return 0, errors.New("read failed")
+}
+
+// Chunks are parsed as they are read, rather than once the whole file
+// has been read
+func TestStreamingLoad(t *testing.T) {
+	noun := "00001740 03 n 01 entity 0 000 | that which exists\n"
+	ld := newLoader()
+	ld.chunkSize = 512
+	r := io.MultiReader(strings.NewReader(strings.Repeat(noun, 100)), parsedReader{t, ld})
+	err := ld.parseChunks("data.noun", r, false)
+	if err == nil || !strings.Contains(err.Error(), "read failed") {
+		t.Fatalf("expected the read to fail, got %v", err)
+	}
+	if len(ld.chunks) < 2 {
+		t.Errorf("expected several chunks, got %d", len(ld.chunks))
+	}
+}
@@ -0,0 +1,52 @@
+package wnram
+
+import (
+	"fmt"
+	"os"
+)
+
+// Open a snapshot written by Handle.WriteSnapshot as a read only
+// database, using its tables in place rather than loading them.  Where
+// the platform allows the file is memory mapped, so that processes
+// using the same snapshot share its pages in the page cache.  Unlike
+// LoadSnapshot the snapshot's crc is not checked, but every position in
+// its tables is checked to be in range, so that a corrupt snapshot is
+// rejected rather than causing a crash.
+//
+// Strings returned by the Handle refer to the mapping, and must not be
+// used after Close.  The snapshot may be stale, see NewMappedChecked.
+func NewMapped(filename string) (*Handle, error) {
+	return NewMappedChecked(filename, "")
+}
+
+// NewMapped, rejecting a snapshot unless it was built from source files
+// with the given checksum, see LoadSnapshotChecked.
+func NewMappedChecked(filename, checksum string) (*Handle, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := mapFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	h, err := fromImage(data, false, checksum)
+	if err != nil {
+		unmapFile(data)
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	h.mapping = data
+	return h, nil
+}
+
+// Release the snapshot mapped by NewMapped.  The Handle is empty
+// afterwards.  Closing a Handle which was not mapped does nothing.
+func (h *Handle) Close() error {
+	if h.mapping == nil {
+		return nil
+	}
+	err := unmapFile(h.mapping)
+	*h = Handle{}
+	return err
+}
@@ -0,0 +1,150 @@
+package wnram
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"unsafe"
+)
+
+func TestNewMapped(t *testing.T) {
+	needWordnet(t)
+	filename := filepath.Join(t.TempDir(), "wordnet.snapshot")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := wnInstance.WriteSnapshot(f); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	h, err := NewMapped(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if h.SourceChecksum() != wnInstance.SourceChecksum() {
+		t.Errorf("expected checksum %s, got %s", wnInstance.SourceChecksum(), h.SourceChecksum())
+	}
+	for _, crit := range []Criteria{
+		{Matching: "good"},
+		{Matching: "yummy", POS: []PartOfSpeech{Adjective}},
+		{Matching: "ran", Morphology: true},
+	} {
+		want, _ := wnInstance.Lookup(crit)
+		got, _ := h.Lookup(crit)
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d results, got %d", crit.Matching, len(want), len(got))
+		}
+		for i := range got {
+			if got[i].DumpStr() != want[i].DumpStr() || got[i].SenseKey() != want[i].SenseKey() {
+				t.Errorf("%s: result %d differs:\n%s\n%s", crit.Matching, i, got[i].DumpStr(), want[i].DumpStr())
+			}
+			wantRel, gotRel := want[i].Related(^Relation(0)), got[i].Related(^Relation(0))
+			if len(gotRel) != len(wantRel) {
+				t.Fatalf("%s: expected %d related, got %d", crit.Matching, len(wantRel), len(gotRel))
+			}
+			for j := range gotRel {
+				if gotRel[j].ID() != wantRel[j].ID() || gotRel[j].Word() != wantRel[j].Word() {
+					t.Errorf("%s: related %d differs: %s %s", crit.Matching, j, gotRel[j].ID(), wantRel[j].ID())
+				}
+			}
+		}
+	}
+
+	var want, got int
+	wnInstance.Iterate(PartOfSpeechList{Verb}, func(Lookup) error { want++; return nil })
+	h.Iterate(PartOfSpeechList{Verb}, func(Lookup) error { got++; return nil })
+	if got != want {
+		t.Errorf("expected to iterate %d verbs, got %d", want, got)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "good"}); len(found) != 0 {
+		t.Errorf("expected nothing from a closed handle, got %d results", len(found))
+	}
+
+	if _, err := NewMapped(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing snapshot")
+	}
+}
+
+// Snapshots whose positions are out of range are rejected, even with a
+// valid crc
+func TestMappedCorrupt(t *testing.T) {
+	fsys := fstest.MapFS{"noun.exc": {Data: []byte("dogs dog\n")}}
+	for name, f := range taxonomyFS {
+		fsys[name] = f
+	}
+	for name, corrupt := range map[string]func(h *Handle){
+		"words":         func(h *Handle) { h.clusters[0].words = uint32(len(h.wordRecs)) },
+		"no words":      func(h *Handle) { h.clusters[0].words, h.clusters[0].nWords = uint32(len(h.wordRecs)), 0 },
+		"sense key":     func(h *Handle) { h.senseKeys = []senseKeyRec{{word: uint32(len(h.wordRecs))}} },
+		"exception pos": func(h *Handle) { h.exceptions[0].pos = uint32(AdjectiveSatellite) },
+		"edges":         func(h *Handle) { h.clusters[0].nEdges = uint16(len(h.edgeRecs) + 1) },
+		"frames":        func(h *Handle) { h.wordRecs[0].frames = uint32(len(h.frames)) + 1 },
+		"gloss":         func(h *Handle) { h.clusters[0].gloss = str{uint32(len(h.text)), 1} },
+		"word":          func(h *Handle) { h.wordRecs[0].cluster = uint32(len(h.clusters)) },
+		"target":        func(h *Handle) { h.edgeRecs[0].cluster = uint32(len(h.clusters)) },
+		"index":         func(h *Handle) { h.targets[0] = uint32(len(h.clusters)) },
+		"index key":     func(h *Handle) { h.index[0].key.off = uint32(len(h.text)) },
+		"index span":    func(h *Handle) { h.index[0].n = uint32(len(h.targets)) + 1 },
+		"exception":     func(h *Handle) { h.exceptions[0].first = uint32(len(h.lists)) },
+		"list":          func(h *Handle) { h.lists[0].off = ^uint32(0) },
+	} {
+		h, err := NewFS(fsys, ".")
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		corrupt(h)
+		filename := filepath.Join(t.TempDir(), "wordnet.snapshot")
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if err := h.WriteSnapshot(f); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if _, err := NewMapped(filename); err == nil {
+			t.Errorf("%s: expected a corrupt snapshot to be rejected when mapped", name)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if _, err := LoadSnapshot(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected a corrupt snapshot to be rejected", name)
+		}
+	}
+}
+
+// The layout of the tables is the snapshot format
+func TestRecordSizes(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"clusterRec", unsafe.Sizeof(clusterRec{}), 40},
+		{"wordRec", unsafe.Sizeof(wordRec{}), 36},
+		{"edgeRec", unsafe.Sizeof(edgeRec{}), 12},
+		{"keyRec", unsafe.Sizeof(keyRec{}), 16},
+		{"excRec", unsafe.Sizeof(excRec{}), 20},
+		{"tagCountRec", unsafe.Sizeof(tagCountRec{}), 16},
+		{"senseKeyRec", unsafe.Sizeof(senseKeyRec{}), 12},
+		{"langRec", unsafe.Sizeof(langRec{}), 24},
+		{"translationRec", unsafe.Sizeof(translationRec{}), 28},
+	} {
+		if c.got != c.want {
+			t.Errorf("expected %s of %d bytes, got %d", c.name, c.want, c.got)
+		}
+	}
+}
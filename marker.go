@@ -0,0 +1,54 @@
+package wnram
+
+import "strings"
+
+// Restrictions on the syntactic position of an adjective, given in
+// parentheses after the word in the data files, e.g. "outback(a)".
+type SyntacticMarker uint8
+
+const (
+	// The adjective is not restricted
+	NoMarker SyntacticMarker = iota
+	// (a) prenominal position, e.g. "an outback town"
+	Attributive
+	// (p) predicate position, e.g. "the book is ready to hand"
+	Predicative
+	// (ip) immediately postnominal position, e.g. "the president elect"
+	ImmediatelyPostnominal
+)
+
+func (m SyntacticMarker) String() string {
+	switch m {
+	case NoMarker:
+		return "none"
+	case Attributive:
+		return "attributive"
+	case Predicative:
+		return "predicative"
+	case ImmediatelyPostnominal:
+		return "immediately postnominal"
+	}
+	return "unknown"
+}
+
+// splitMarker separates a syntactic marker from a word
+func splitMarker(w string) (string, SyntacticMarker) {
+	for suffix, m := range map[string]SyntacticMarker{
+		"(a)":  Attributive,
+		"(p)":  Predicative,
+		"(ip)": ImmediatelyPostnominal,
+	} {
+		if strings.HasSuffix(w, suffix) {
+			return strings.TrimSuffix(w, suffix), m
+		}
+	}
+	return w, NoMarker
+}
+
+// The syntactic position this adjective is restricted to, if any
+func (w *Lookup) SyntacticMarker() SyntacticMarker {
+	if word := w.wordRec(); word != nil {
+		return word.marker
+	}
+	return NoMarker
+}
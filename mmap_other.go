@@ -0,0 +1,18 @@
+//go:build !unix
+
+package wnram
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads a file where memory mapping is not supported
+func mapFile(f *os.File) ([]byte, error) {
+	data, err := io.ReadAll(f)
+	return aligned(data), err
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,200 @@
+package wnram
+
+import (
+	"sort"
+	"strings"
+)
+
+// A detachment rule maps an inflectional suffix to the ending of the
+// base form, e.g. "ches" -> "ch" turns "churches" into "church".
+type detachment struct {
+	suffix string
+	ending string
+}
+
+// The per part of speech detachment rules from WordNet's morphy(7WN).
+var detachmentRules = map[PartOfSpeech][]detachment{
+	Noun: {
+		{"s", ""},
+		{"ses", "s"},
+		{"xes", "x"},
+		{"zes", "z"},
+		{"ches", "ch"},
+		{"shes", "sh"},
+		{"men", "man"},
+		{"ies", "y"},
+	},
+	Verb: {
+		{"s", ""},
+		{"ies", "y"},
+		{"es", "e"},
+		{"es", ""},
+		{"ed", "e"},
+		{"ed", ""},
+		{"ing", "e"},
+		{"ing", ""},
+	},
+	Adjective: {
+		{"er", ""},
+		{"est", ""},
+		{"er", "e"},
+		{"est", "e"},
+	},
+}
+
+// exceptions holds the contents of a single *.exc file, mapping an
+// irregular inflected form to its base forms.
+type exceptions map[string][]string
+
+// excPOS identifies the part of speech of an exception list from its
+// file name (noun.exc, verb.exc, adj.exc or adv.exc)
+func excPOS(base string) (PartOfSpeech, bool) {
+	if !strings.HasSuffix(base, ".exc") {
+		return 0, false
+	}
+	return posFromName(strings.TrimSuffix(base, ".exc"))
+}
+
+// exceptionBases returns the base forms of an irregular inflected
+// form from the exception lists
+func (h *Handle) exceptionBases(pos PartOfSpeech, form string) ([]string, bool) {
+	i := sort.Search(len(h.exceptions), func(i int) bool {
+		r := &h.exceptions[i]
+		return PartOfSpeech(r.pos) > pos || (PartOfSpeech(r.pos) == pos && h.str(r.key) >= form)
+	})
+	if i < len(h.exceptions) && PartOfSpeech(h.exceptions[i].pos) == pos && h.str(h.exceptions[i].key) == form {
+		return h.strs(h.exceptions[i].first, h.exceptions[i].n), true
+	}
+	return nil, false
+}
+
+// hasLemma reports whether a normalized string is indexed with the
+// given part of speech.
+func (h *Handle) hasLemma(form string, pos PartOfSpeech) bool {
+	for _, c := range h.lookupIndex(form) {
+		if h.clusters[c].pos == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// filterForms returns the unique forms which are present in the
+// database as the given part of speech, preserving order.
+func (h *Handle) filterForms(forms []string, pos PartOfSpeech) (found []string) {
+	for i, f := range forms {
+		if !h.hasLemma(f, pos) {
+			continue
+		}
+		dup := false
+		for _, g := range forms[:i] {
+			if g == f {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// Lemmatize returns the base forms of word which are present in the
+// database as the given part of speech, e.g. "geese" yields "goose"
+// and "running" yields "run".  Irregular forms are resolved using the
+// exception lists (*.exc) when they were available at load time,
+// after which the regular detachment rules are applied.  The word
+// itself is included when it is a base form.  An empty result means
+// no base form is known.  Adjective satellites are treated as
+// adjectives.
+func (h *Handle) Lemmatize(word string, pos PartOfSpeech) []string {
+	if pos == AdjectiveSatellite {
+		pos = Adjective
+	}
+	word = normalize(word)
+	if word == "" {
+		return nil
+	}
+	if bases, ok := h.exceptionBases(pos, word); ok {
+		return h.filterForms(append([]string{word}, bases...), pos)
+	}
+	if strings.Contains(word, " ") {
+		return h.morphCollocation(word, pos)
+	}
+	return h.morphWord(word, pos)
+}
+
+// morphWord applies detachment rules to a single word.
+func (h *Handle) morphWord(word string, pos PartOfSpeech) []string {
+	forms := []string{word}
+	if pos == Noun {
+		if strings.HasSuffix(word, "ful") {
+			// "boxesful" -> "boxful"
+			var found []string
+			for _, base := range h.morphWord(strings.TrimSuffix(word, "ful"), pos) {
+				found = append(found, base+"ful")
+			}
+			return h.filterForms(append(forms, found...), pos)
+		}
+		if strings.HasSuffix(word, "ss") || len(word) <= 2 {
+			return h.filterForms(forms, pos)
+		}
+	}
+	for _, d := range detachmentRules[pos] {
+		if strings.HasSuffix(word, d.suffix) && len(word) > len(d.suffix) {
+			forms = append(forms, strings.TrimSuffix(word, d.suffix)+d.ending)
+		}
+	}
+	return h.filterForms(forms, pos)
+}
+
+// morphCollocation handles multi-word strings.  Verb collocations only
+// inflect the first word ("looking up" -> "look up"), other parts of
+// speech may inflect any word ("attorneys general" -> "attorney
+// general").
+func (h *Handle) morphCollocation(word string, pos PartOfSpeech) []string {
+	parts := strings.Split(word, " ")
+	for i, p := range parts {
+		if pos == Verb && i > 0 {
+			break
+		}
+		base := p
+		if bases, ok := h.exceptionBases(pos, p); ok {
+			base = bases[0]
+		} else if bases := h.morphWord(p, pos); len(bases) > 0 {
+			base = bases[0]
+		}
+		parts[i] = base
+	}
+	return h.filterForms([]string{word, strings.Join(parts, " ")}, pos)
+}
+
+// lookupBaseForms finds clusters for all base forms of the search
+// string, see Criteria.Morphology.
+func (h *Handle) lookupBaseForms(crit Criteria) []Lookup {
+	pos := crit.POS
+	if pos.Empty() {
+		pos = PartOfSpeechList{Noun, Verb, Adjective, Adverb}
+	}
+	searchStr := normalize(crit.Matching)
+	found := []Lookup{}
+	seen := map[uint32]bool{}
+	for _, p := range pos {
+		for _, base := range h.Lemmatize(searchStr, p) {
+			query := base
+			if base == searchStr {
+				query = crit.Matching
+			}
+			for _, c := range h.lookupIndex(base) {
+				l := Lookup{h: h, cluster: c, word: h.findWord(c, base), query: query}
+				if !(PartOfSpeechList{p}).matches(&h.clusters[c]) || !crit.matches(&l) || seen[c] {
+					continue
+				}
+				seen[c] = true
+				found = append(found, l)
+			}
+		}
+	}
+	return found
+}
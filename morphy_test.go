@@ -0,0 +1,98 @@
+package wnram
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// A few lemmas, their index entries and the exception lists
+var morphyFS = fstest.MapFS{
+	"data.noun": {Data: []byte(
+		"00000010 05 n 01 dog 0 000 | a member of the genus Canis\n" +
+			"00000020 05 n 01 goose 0 000 | web-footed long-necked bird\n" +
+			"00000030 06 n 01 church 0 000 | a place for public worship\n" +
+			"00000040 27 n 01 glass 0 000 | a brittle transparent solid\n")},
+	"data.verb": {Data: []byte(
+		"00000010 38 v 01 run 0 000 01 + 02 00 | move fast by using one's feet\n" +
+			"00000020 39 v 01 look_up 0 000 01 + 08 00 | seek information from\n" +
+			"00000030 39 v 01 look 0 000 01 + 02 00 | perceive with attention\n")},
+	"data.adj": {Data: []byte(
+		"00000010 00 a 01 good 0 000 | having desirable or positive qualities\n")},
+	"index.noun": {Data: []byte(
+		"church n 1 0 1 0 00000030  \n" +
+			"dog n 1 0 1 0 00000010  \n" +
+			"glass n 1 0 1 0 00000040  \n" +
+			"goose n 1 0 1 0 00000020  \n")},
+	"index.verb": {Data: []byte(
+		"look v 1 0 1 0 00000030  \n" +
+			"look_up v 1 0 1 0 00000020  \n" +
+			"run v 1 0 1 0 00000010  \n")},
+	"index.adj": {Data: []byte(
+		"good a 1 0 1 0 00000010  \n")},
+	"noun.exc": {Data: []byte("geese goose\n")},
+	"verb.exc": {Data: []byte("ran run\nrunning run\n")},
+	"adj.exc":  {Data: []byte("better good well\n")},
+}
+
+func newMorphyHandle(t *testing.T) *Handle {
+	t.Helper()
+	h, err := NewFS(morphyFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	return h
+}
+
+func TestLemmatize(t *testing.T) {
+	h := newMorphyHandle(t)
+	cases := []struct {
+		word string
+		pos  PartOfSpeech
+		want string
+	}{
+		{"running", Verb, "run"},
+		{"ran", Verb, "run"},
+		{"geese", Noun, "goose"},
+		{"dogs", Noun, "dog"},
+		{"churches", Noun, "church"},
+		{"better", Adjective, "good"},
+		{"looking up", Verb, "look up"},
+		{"run", Verb, "run"},
+	}
+	for _, c := range cases {
+		got := h.Lemmatize(c.word, c.pos)
+		if !setContains(got, []string{c.want}) {
+			t.Errorf("Lemmatize(%q, %s) = %v, want %q", c.word, c.pos, got, c.want)
+		}
+	}
+	if got := h.Lemmatize("glass", Noun); len(got) != 1 || got[0] != "glass" {
+		t.Errorf("Lemmatize(glass) = %v, nouns ending in ss must be left alone", got)
+	}
+	if got := h.Lemmatize("xyzzyies", Noun); len(got) != 0 {
+		t.Errorf("Lemmatize(xyzzyies) = %v, expected no base forms", got)
+	}
+}
+
+func TestLookupMorphology(t *testing.T) {
+	h := newMorphyHandle(t)
+	found, err := h.Lookup(Criteria{Matching: "ran", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no exact match for ran, got %d", len(found))
+	}
+
+	found, err = h.Lookup(Criteria{Matching: "ran", POS: []PartOfSpeech{Verb}, Morphology: true})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) == 0 {
+		t.Fatalf("expected base forms of ran to be found")
+	}
+	for _, f := range found {
+		if f.Word() != "run" || f.POS() != Verb {
+			t.Errorf("unexpected match for ran: %s", f.String())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package wnram
+
+import (
+	"sort"
+	"strings"
+)
+
+// Lemmas, definitions and examples of synsets in a language other than
+// English, as distributed by the Open Multilingual Wordnet, while
+// loading.
+type language struct {
+	index       map[string][]*cluster
+	lemmas      map[*cluster][]string
+	definitions map[*cluster][]string
+	examples    map[*cluster][]string
+}
+
+// omwLanguage returns the language of an Open Multilingual Wordnet tab
+// file from its name, e.g. "fra" for wn-data-fra.tab
+func omwLanguage(filename string) (string, bool) {
+	if !strings.HasPrefix(filename, "wn-data-") || !strings.HasSuffix(filename, ".tab") {
+		return "", false
+	}
+	lang := strings.TrimSuffix(strings.TrimPrefix(filename, "wn-data-"), ".tab")
+	return lang, lang != ""
+}
+
+// addTranslation attaches a line of an Open Multilingual Wordnet tab
+// file to its cluster.  Other types of line are ignored.
+func addTranslation(languages map[string]*language, t *parsedTranslation, c *cluster) {
+	lang, ok := languages[t.lang]
+	if !ok {
+		lang = &language{
+			index:       map[string][]*cluster{},
+			lemmas:      map[*cluster][]string{},
+			definitions: map[*cluster][]string{},
+			examples:    map[*cluster][]string{},
+		}
+		languages[t.lang] = lang
+	}
+	switch t.kind {
+	case "lemma":
+		key := normalize(t.value)
+		for _, found := range lang.index[key] {
+			if found == c {
+				return
+			}
+		}
+		lang.index[key] = append(lang.index[key], c)
+		lang.lemmas[c] = append(lang.lemmas[c], t.value)
+	case "def":
+		lang.definitions[c] = append(lang.definitions[c], t.value)
+	case "exe":
+		lang.examples[c] = append(lang.examples[c], t.value)
+	}
+}
+
+// language finds a language by its code
+func (h *Handle) language(code string) (langRec, bool) {
+	for _, l := range h.languages {
+		if h.str(l.code) == code {
+			return l, true
+		}
+	}
+	return langRec{}, false
+}
+
+// translation finds the lemmas, definitions and examples of a cluster
+// in a language
+func (h *Handle) translation(code string, c uint32) (translationRec, bool) {
+	l, ok := h.language(code)
+	if !ok {
+		return translationRec{}, false
+	}
+	return h.langTranslation(l, c)
+}
+
+func (h *Handle) langTranslation(l langRec, c uint32) (translationRec, bool) {
+	ts := h.translations[l.translations : l.translations+l.nTrans]
+	i := sort.Search(len(ts), func(i int) bool {
+		return ts[i].cluster >= c
+	})
+	if i < len(ts) && ts[i].cluster == c {
+		return ts[i], true
+	}
+	return translationRec{}, false
+}
+
+// findTranslation returns the lemma of a cluster in a language which
+// normalizes to key, as a foreignWord
+func (h *Handle) findTranslation(lang langRec, c uint32, key string) uint32 {
+	t, _ := h.langTranslation(lang, c)
+	for i := t.lemmas; i < t.lemmas+t.nLemmas; i++ {
+		if normalize(h.str(h.lists[i])) == key {
+			return foreignWord | i
+		}
+	}
+	return foreignWord | t.lemmas
+}
+
+// The languages other than English for which lemmas are loaded, as
+// ISO 639-3 codes, e.g. "fra".  See Criteria.Language.
+func (h *Handle) Languages() []string {
+	var langs []string
+	for _, l := range h.languages {
+		langs = append(langs, h.str(l.code))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// The lemmas of this meaning in another language, e.g. "chien" in
+// "fra" for "dog".
+func (w *Lookup) Translations(lang string) []string {
+	t, _ := w.h.translation(lang, w.cluster)
+	return w.h.strs(t.lemmas, t.nLemmas)
+}
+
+// The definition of this meaning in another language, if any
+func (w *Lookup) TranslatedDefinition(lang string) string {
+	t, _ := w.h.translation(lang, w.cluster)
+	return strings.Join(w.h.strs(t.definitions, t.nDefinitions), "; ")
+}
+
+// Example sentences for this meaning in another language
+func (w *Lookup) TranslatedExamples(lang string) []string {
+	t, _ := w.h.translation(lang, w.cluster)
+	return w.h.strs(t.examples, t.nExamples)
+}
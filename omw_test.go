@@ -0,0 +1,61 @@
+package wnram
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranslations(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"data.noun": "00000010 05 n 01 dog 0 001 @ 00000100 n 0000 | a member of the genus Canis\n" +
+			"00000100 05 n 01 canine 0 001 ~ 00000010 n 0000 | any of various fissiped mammals\n",
+		"wn-data-fra.tab": "# Open Multilingual Wordnet French\tfra\thttp://example.com\tCeCILL-C\n" +
+			"00000010-n\tfra:lemma\tchien\n" +
+			"00000010-n\tfra:lemma\tchien_de_compagnie\n" +
+			"00000010-n\tfra:def\t0\tmammifère domestique\n" +
+			"00000010-n\tfra:exe\t0\tle chien aboie\n" +
+			"00000100-n\tfra:lemma\tcanidé\n" +
+			"00000999-n\tfra:lemma\tinconnu\n",
+		"wn-data-deu.tab": "00000010-n\tlemma\tHund\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := New(dir)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if langs := h.Languages(); len(langs) != 2 || langs[0] != "deu" || langs[1] != "fra" {
+		t.Errorf("expected deu and fra, got %v", langs)
+	}
+
+	found, err := h.Lookup(Criteria{Matching: "Chien de compagnie", Language: "fra"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 || found[0].Lemma() != "dog" {
+		t.Fatalf("expected chien de compagnie to find dog, got %v", found)
+	}
+	dog := found[0]
+	if !setContains(dog.Translations("fra"), []string{"chien", "chien de compagnie"}) {
+		t.Errorf("unexpected french lemmas for dog: %v", dog.Translations("fra"))
+	}
+	if dog.TranslatedDefinition("fra") != "mammifère domestique" || len(dog.TranslatedExamples("fra")) != 1 {
+		t.Errorf("unexpected french gloss for dog")
+	}
+	if hypernyms := dog.Related(Hypernym); len(hypernyms) != 1 || hypernyms[0].Translations("fra")[0] != "canidé" {
+		t.Errorf("expected canidé as hypernym of chien")
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "hund", Language: "deu"}); len(found) != 1 || found[0].Lemma() != "dog" {
+		t.Errorf("expected hund to find dog")
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "chien"}); len(found) != 0 {
+		t.Errorf("expected english lookups to ignore french lemmas")
+	}
+	if _, err := h.Lookup(Criteria{Matching: "chien", Language: "ita"}); err == nil {
+		t.Errorf("expected an error for a language which is not loaded")
+	}
+}
@@ -0,0 +1,63 @@
+package wnram
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+)
+
+// Options control how a database is loaded, see NewWithOptions
+type Options struct {
+	// Skip lines which cannot be parsed, and relations, index entries
+	// and sense keys which refer to missing synsets or words, rather
+	// than failing at the first error.  The database is returned along
+	// with an ErrorList of everything that was skipped.
+	Lenient bool
+	// Logs each file read, with its size and the time taken, and the
+	// errors skipped when lenient.  Nothing is logged if nil.
+	Logger *slog.Logger
+	// Called as files are read, several times for large files
+	Progress func(Progress)
+	// Loading stops, returning the context's error, once it is done
+	Context context.Context
+	// The parts of speech to load, or all if empty.  Relations to
+	// synsets of other parts of speech are dropped.  Adjective includes
+	// satellites, and AdjectiveSatellite loads all adjectives too, as
+	// satellites are defined by the adjectives they are similar to.
+	POS PartOfSpeechList
+}
+
+// Progress reports how much of a file has been read
+type Progress struct {
+	File  string
+	Bytes int64 // bytes read so far
+	Lines int64 // lines read so far
+	Done  bool  // set when the whole file has been read
+}
+
+// Initialize a new in-ram WordNet database as New does, with options.
+// Load errors are *ParseError, or an ErrorList when lenient.
+func NewWithOptions(dir string, opts Options) (*Handle, error) {
+	return newFS(os.DirFS(dir), ".", opts)
+}
+
+// Initialize a new in-ram WordNet database as NewFS does, with options.
+func NewFSWithOptions(fsys fs.FS, root string, opts Options) (*Handle, error) {
+	return newFS(fsys, root, opts)
+}
+
+func newFS(fsys fs.FS, root string, opts Options) (*Handle, error) {
+	ld := newLoader()
+	ld.lenient = opts.Lenient
+	ld.logger = opts.Logger
+	ld.progress = opts.Progress
+	ld.pos = opts.POS
+	if opts.Context != nil {
+		ld.ctx = opts.Context
+	}
+	if err := ld.loadAll(fsys, root); err != nil {
+		return nil, err
+	}
+	return ld.handle()
+}
@@ -0,0 +1,106 @@
+package wnram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/lloyd/wnram/data"
+)
+
+func TestOptions(t *testing.T) {
+	var log bytes.Buffer
+	var progress []Progress
+	h, err := NewWithOptions(sourceCodeRelPath(PathToWordnetDataFiles), Options{
+		Logger:   slog.New(slog.NewTextHandler(&log, nil)),
+		Progress: func(p Progress) { progress = append(progress, p) },
+		POS:      []PartOfSpeech{Verb},
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// only verbs are loaded, without relations to other synsets
+	found, err := h.Lookup(Criteria{Matching: "run"})
+	if err != nil || len(found) == 0 {
+		t.Fatalf("expected to find run: %v", err)
+	}
+	for _, f := range found {
+		if f.POS() != Verb {
+			t.Errorf("expected only verbs, got %s", f.String())
+		}
+		for _, r := range f.Related(^Relation(0)) {
+			if r.POS() != Verb {
+				t.Errorf("expected only relations to verbs, got %s", r.String())
+			}
+		}
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "dog", POS: []PartOfSpeech{Noun}}); len(found) != 0 {
+		t.Errorf("expected no nouns, got %d", len(found))
+	}
+
+	var done *Progress
+	for i, p := range progress {
+		if strings.HasSuffix(p.File, "data.noun") {
+			t.Errorf("expected data.noun to be skipped")
+		} else if strings.HasSuffix(p.File, "data.verb") && p.Done {
+			done = &progress[i]
+		}
+	}
+	if done == nil || done.Bytes == 0 || done.Lines == 0 {
+		t.Errorf("expected progress for data.verb, got %+v", progress)
+	}
+	if !strings.Contains(log.String(), "data.verb") {
+		t.Errorf("expected data.verb to be logged, got %q", log.String())
+	}
+}
+
+// Satellites are loaded from the adjective files
+func TestOptionsSatellites(t *testing.T) {
+	needWordnet(t, Adjective)
+	h, err := NewWithOptions(sourceCodeRelPath(PathToWordnetDataFiles), Options{POS: []PartOfSpeech{AdjectiveSatellite}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found, err := h.Lookup(Criteria{Matching: "yummy", POS: []PartOfSpeech{AdjectiveSatellite}})
+	if err != nil || len(found) == 0 {
+		t.Fatalf("expected to find the satellite yummy: %v", err)
+	}
+	if !found[0].IsSatellite() || found[0].SenseKey() == "" {
+		t.Errorf("unexpected sense of yummy: %s", found[0].String())
+	}
+	if found, _ := h.Lookup(Criteria{Matching: "run", POS: []PartOfSpeech{Verb}}); len(found) != 0 {
+		t.Errorf("expected no verbs, got %d", len(found))
+	}
+}
+
+func TestOptionsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewWithOptions(sourceCodeRelPath(PathToWordnetDataFiles), Options{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected loading to be canceled, got %v", err)
+	}
+}
+
+// The embedded files load without nouns, which are not bundled
+func TestEmbeddedData(t *testing.T) {
+	h, err := NewFSWithOptions(data.FS, ".", Options{POS: PartOfSpeechList{Verb, Adjective, Adverb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for _, pos := range []PartOfSpeech{Verb, Adjective, Adverb} {
+		var n int
+		h.Iterate(PartOfSpeechList{pos}, func(Lookup) error { n++; return nil })
+		if n == 0 {
+			t.Errorf("expected %s synsets from the embedded files", pos)
+		}
+	}
+	if _, err := fs.Stat(data.FS, "embed.go"); err == nil {
+		t.Errorf("expected only data files to be embedded")
+	}
+}
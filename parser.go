@@ -175,10 +175,10 @@ func (l *lexable) lexPOS() (PartOfSpeech, error) {
 	case 'a':
 		return Adjective, nil
 	case 's':
-		// XXX: note, that an adjective is not the core of an adj cluster is not
+		// note, that an adjective is not the core of an adj cluster is not
 		// really related to the part of speech.  its more like encoding a relationship
-		// between the adjective and the head cluster.  For now, let's just smash it into
-		// adjective.
+		// between the adjective and the head cluster.  Satellites are adjectives,
+		// parseLine records satellite status separately.
 		return Adjective, nil
 	case 'r':
 		return Adverb, nil
@@ -247,7 +247,7 @@ func (l *lexable) lexRelationType() (Relation, error) {
 	case "~i":
 		return InstanceHyponym, nil
 	}
-	return 0, fmt.Errorf("unrecognized pointer type: %q", word)
+	return 0, fmt.Errorf("%w: %q", ErrUnknownPointer, word)
 }
 
 type parsedRel struct {
@@ -258,15 +258,32 @@ type parsedRel struct {
 	source, dest uint8
 }
 
+// A verb frame applies to a single word, or to all words when word is
+// 0xff
+type parsedFrame struct {
+	frame uint8
+	word  uint8
+}
+
+// The number and byte offset of a parsed line within its file
+type position struct {
+	line, offset int64
+}
+
 type parsed struct {
+	position
 	byteOffset string
 	pos        PartOfSpeech
+	satellite  bool
 	fileNum    int64
 	words      []word
 	gloss      string
 	rels       []parsedRel
+	frames     []parsedFrame
 }
 
+// parseLine parses a line of a data file.  Errors are located with
+// syntaxErr at the token which could not be parsed.
 func parseLine(data []byte, line, offset int64) (*parsed, error) {
 	l := lexable(data)
 
@@ -279,90 +296,350 @@ func parseLine(data []byte, line, offset int64) (*parsed, error) {
 			// comment!
 			return nil, nil
 		}
-		return nil, fmt.Errorf("can't parse line, expected comment or Offset")
+		return nil, syntaxErr(data, lexable(data), fmt.Errorf("can't parse line, expected comment or Offset"))
 	}
 	// file number
+	before := l
 	filenum, err := l.lexDecimalNumber()
 	if err != nil {
-		return nil, fmt.Errorf("filenumber expected: %s", err)
+		return nil, syntaxErr(data, before, fmt.Errorf("filenumber expected: %s", err))
 	}
+	// adjective satellites are recorded as adjectives with a flag
+	l.chomp()
+	satellite := strings.HasPrefix(string(l), "s")
+	before = l
 	pos, err := l.lexPOS()
 	if err != nil {
-		return nil, fmt.Errorf("part of speech expected: %s", err)
+		return nil, syntaxErr(data, before, fmt.Errorf("part of speech expected: %s", err))
 	}
 	// lexicographer file containing the word
+	before = l
 	wordcount, err := l.lexHexNumber()
 	if err != nil {
-		return nil, fmt.Errorf("wordcount expected: %s", err)
+		return nil, syntaxErr(data, before, fmt.Errorf("wordcount expected: %s", err))
 	}
 	p := parsed{
+		position:   position{line, offset},
 		byteOffset: byteOffset,
 		pos:        pos,
+		satellite:  satellite,
 		fileNum:    filenum,
 	}
 	for ; wordcount > 0; wordcount-- {
+		before = l
 		value, err := l.lexWord()
 		if err != nil {
-			return nil, fmt.Errorf("word expected: %s")
+			return nil, syntaxErr(data, before, fmt.Errorf("word expected: %s", err))
 		}
-		// XXX: handle syntactic markers
+		value, marker := splitMarker(value)
+		before = l
 		sense, err := l.lexHexNumber()
 		if err != nil {
-			return nil, fmt.Errorf("sense id expected: %s")
+			return nil, syntaxErr(data, before, fmt.Errorf("sense id expected: %s", err))
 		}
 		p.words = append(p.words, word{
-			word:  value,
-			sense: uint8(sense),
+			word:   value,
+			sense:  uint8(sense),
+			marker: marker,
 		})
 	}
+	before = l
 	pcount, err := l.lexDecimalNumber()
 	if err != nil {
-		return nil, fmt.Errorf("pointer count expected: %s", err)
+		return nil, syntaxErr(data, before, fmt.Errorf("pointer count expected: %s", err))
 	}
 	for ; pcount > 0; pcount-- {
-		if rt, err := l.lexRelationType(); err != nil {
-			return nil, err
-		} else if offset, err := l.lexOffset(); err != nil {
-			return nil, err
-		} else if pos, err := l.lexPOS(); err != nil {
-			return nil, err
-		} else if nature, err := l.lexHexNumber(); err != nil {
-			return nil, err
+		before = l
+		rt, err := l.lexRelationType()
+		if err != nil {
+			return nil, syntaxErr(data, before, err)
+		}
+		before = l
+		offset, err := l.lexOffset()
+		if err != nil {
+			return nil, syntaxErr(data, before, err)
+		}
+		before = l
+		pos, err := l.lexPOS()
+		if err != nil {
+			return nil, syntaxErr(data, before, err)
+		}
+		before = l
+		nature, err := l.lexHexNumber()
+		if err != nil {
+			return nil, syntaxErr(data, before, err)
+		}
+		r := parsedRel{
+			rel:    rt,
+			pos:    pos,
+			offset: offset,
+		}
+		if nature == 0 {
+			r.isSemantic = true
 		} else {
-			r := parsedRel{
-				rel:    rt,
-				pos:    pos,
-				offset: offset,
-			}
-			if nature == 0 {
-				r.isSemantic = true
-			} else {
-				r.isSemantic = false
-				r.source = uint8(nature>>8) - 1
-				r.dest = uint8(nature&0xff) - 1
-			}
-			p.rels = append(p.rels, r)
+			r.isSemantic = false
+			r.source = uint8(nature>>8) - 1
+			r.dest = uint8(nature&0xff) - 1
 		}
+		p.rels = append(p.rels, r)
 	}
 	// parse optional frame count
 	frameCount, err := l.lexDecimalNumber()
 	if err == nil {
 		for ; frameCount > 0; frameCount-- {
 			l.chomp()
+			before = l
 			if r, ok := l.next(); !ok || r != '+' {
-				return nil, fmt.Errorf("missing frame marker (+)")
-			} else if _, err := l.lexDecimalNumber(); err != nil {
-				return nil, fmt.Errorf("malformed frame number: %s", err)
-			} else if _, err := l.lexHexNumber(); err != nil {
-				return nil, fmt.Errorf("malformed word number in frame: %s", err)
+				return nil, syntaxErr(data, before, fmt.Errorf("missing frame marker (+)"))
+			}
+			before = l
+			frame, err := l.lexDecimalNumber()
+			if err != nil {
+				return nil, syntaxErr(data, before, fmt.Errorf("malformed frame number: %s", err))
 			}
+			before = l
+			wordNumber, err := l.lexHexNumber()
+			if err != nil {
+				return nil, syntaxErr(data, before, fmt.Errorf("malformed word number in frame: %s", err))
+			}
+			p.frames = append(p.frames, parsedFrame{
+				frame: uint8(frame),
+				word:  uint8(wordNumber) - 1,
+			})
 		}
 	}
+	before = l
 	gloss, err := l.lexGloss()
 	if err != nil {
-		return nil, err
+		return nil, syntaxErr(data, before, err)
 	}
 	p.gloss = gloss
 
 	return &p, nil
 }
+
+// parseExceptionLine parses a line of a morphological exception list
+// (*.exc): an inflected form followed by one or more base forms.
+func parseExceptionLine(data []byte) (string, []string, error) {
+	l := lexable(data)
+	inflected, err := l.lexWord()
+	if err != nil || inflected == "" {
+		return "", nil, fmt.Errorf("inflected form expected")
+	}
+	var bases []string
+	for l.chomp(); !l.empty(); l.chomp() {
+		base, err := l.lexWord()
+		if err != nil {
+			return "", nil, err
+		}
+		bases = append(bases, base)
+	}
+	if len(bases) == 0 {
+		return "", nil, fmt.Errorf("no base form for %q", inflected)
+	}
+	return inflected, bases, nil
+}
+
+type parsedIndex struct {
+	position
+	lemma         string
+	pos           PartOfSpeech
+	tagSenseCount int64
+	offsets       []string
+}
+
+// parseIndexLine parses a line of an index file (index.noun, ...).
+// Returns nil for license lines, which are indented.
+func parseIndexLine(data []byte) (*parsedIndex, error) {
+	if len(data) == 0 || data[0] == ' ' {
+		return nil, nil
+	}
+	l := lexable(data)
+	lemma, err := l.lexWord()
+	if err != nil {
+		return nil, fmt.Errorf("lemma expected: %s", err)
+	}
+	pos, err := l.lexPOS()
+	if err != nil {
+		return nil, fmt.Errorf("part of speech expected: %s", err)
+	}
+	synsetCount, err := l.lexDecimalNumber()
+	if err != nil {
+		return nil, fmt.Errorf("synset count expected: %s", err)
+	}
+	pcount, err := l.lexDecimalNumber()
+	if err != nil {
+		return nil, fmt.Errorf("pointer count expected: %s", err)
+	}
+	for ; pcount > 0; pcount-- {
+		if _, err := l.lexWord(); err != nil {
+			return nil, fmt.Errorf("pointer symbol expected: %s", err)
+		}
+	}
+	// sense_cnt is redundant with synset_cnt
+	if _, err := l.lexDecimalNumber(); err != nil {
+		return nil, fmt.Errorf("sense count expected: %s", err)
+	}
+	tagSenseCount, err := l.lexDecimalNumber()
+	if err != nil {
+		return nil, fmt.Errorf("tagsense count expected: %s", err)
+	}
+	p := parsedIndex{
+		lemma:         lemma,
+		pos:           pos,
+		tagSenseCount: tagSenseCount,
+	}
+	for ; synsetCount > 0; synsetCount-- {
+		offset, err := l.lexOffset()
+		if err != nil {
+			return nil, err
+		}
+		p.offsets = append(p.offsets, offset)
+	}
+	return &p, nil
+}
+
+type parsedSense struct {
+	position
+	key         string
+	synset      string
+	senseNumber int64
+	tagCount    int64
+}
+
+// parseSenseIndexLine parses a line of index.sense.  Sense keys are
+// read verbatim, as underscores are significant.
+func parseSenseIndexLine(data []byte) (*parsedSense, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("expected 4 fields, got %d", len(fields))
+	}
+	l := lexable(fields[1])
+	offset, err := l.lexOffset()
+	if err != nil {
+		return nil, err
+	}
+	senseNumber, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("sense number expected: %s", err)
+	}
+	tagCount, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("tag count expected: %s", err)
+	}
+	return &parsedSense{
+		key:         fields[0],
+		synset:      offset,
+		senseNumber: senseNumber,
+		tagCount:    tagCount,
+	}, nil
+}
+
+// parseSentenceIndexLine parses a line of sentidx.vrb: a sense key
+// followed by a comma separated list of example sentence numbers.
+func parseSentenceIndexLine(data []byte) (string, []int, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 1 {
+		return fields[0], nil, nil
+	} else if len(fields) != 2 {
+		return "", nil, fmt.Errorf("expected sense key and sentence numbers")
+	}
+	var nums []int
+	for _, n := range strings.Split(fields[1], ",") {
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return "", nil, fmt.Errorf("malformed sentence number: %s", err)
+		}
+		nums = append(nums, i)
+	}
+	return fields[0], nums, nil
+}
+
+// parseSentenceLine parses a line of sents.vrb: a sentence number
+// followed by an example sentence with %s in place of the verb.
+func parseSentenceLine(data []byte) (int, string, error) {
+	l := lexable(data)
+	n, err := l.lexDecimalNumber()
+	if err != nil {
+		return 0, "", fmt.Errorf("sentence number expected: %s", err)
+	}
+	return int(n), strings.TrimSpace(string(l)), nil
+}
+
+// parseLexnameLine parses a line of the lexnames file: a file number,
+// the file name and its syntactic category.
+func parseLexnameLine(data []byte) (int, string, error) {
+	l := lexable(data)
+	n, err := l.lexDecimalNumber()
+	if err != nil {
+		return 0, "", fmt.Errorf("file number expected: %s", err)
+	}
+	name, err := l.lexWord()
+	if err != nil || name == "" {
+		return 0, "", fmt.Errorf("file name expected")
+	}
+	return int(n), name, nil
+}
+
+// parseCountLine parses a line of cntlist.rev (sense_key sense_number
+// tag_cnt) or, when reversed is false, of cntlist (tag_cnt sense_key
+// sense_number).
+func parseCountLine(data []byte, reversed bool) (string, int64, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return "", 0, fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+	key, count := fields[1], fields[0]
+	if reversed {
+		key, count = fields[0], fields[2]
+	}
+	n, err := strconv.ParseInt(count, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("tag count expected: %s", err)
+	}
+	return key, n, nil
+}
+
+// A line of an Open Multilingual Wordnet tab file
+type parsedTranslation struct {
+	synset offsetKey
+	lang   string // empty unless the type is prefixed, e.g. "fra:lemma"
+	kind   string // lemma, def or exe
+	value  string
+}
+
+// parseTranslationLine parses a line of an Open Multilingual Wordnet tab
+// file: a synset id (offset-pos), a type, and a value, separated by
+// tabs.  Definitions and examples have a number before the value.
+// Comments and blank lines return nil.
+func parseTranslationLine(data []byte) (*parsedTranslation, error) {
+	line := strings.TrimRight(string(data), "\r\n")
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("expected synset, type and value")
+	}
+	key, err := parseSynsetID(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	p := parsedTranslation{synset: key, kind: fields[1]}
+	if i := strings.IndexByte(p.kind, ':'); i >= 0 {
+		p.lang, p.kind = p.kind[:i], p.kind[i+1:]
+	}
+	p.value = fields[2]
+	if p.kind == "def" || p.kind == "exe" {
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("expected number and text for %s", p.kind)
+		}
+		p.value = fields[3]
+	}
+	if p.kind == "lemma" {
+		// as in the data files, underscores separate words
+		p.value = strings.Replace(p.value, "_", " ", -1)
+	}
+	p.value = strings.TrimSpace(p.value)
+	return &p, nil
+}
@@ -0,0 +1,246 @@
+package wnram
+
+import "container/heap"
+
+// A step of a path between meanings, see Handle.ShortestPath
+type Step struct {
+	// The meaning reached.  Its word is the one with the lexical
+	// relation followed, if any.
+	Lookup
+	// The relation followed to reach it from the previous step, zero for
+	// the first step
+	Relation Relation
+	// Whether the relation was followed backwards, i.e. it is this step
+	// which has the relation to the previous one.  Following a hypernym
+	// backwards reaches a hyponym.
+	Reverse bool
+}
+
+// Options for Handle.ShortestPath
+type PathOptions struct {
+	// The cost of following each relation, which must not be negative.
+	// Relations which are not listed cost 1, so that by default the
+	// path with the fewest steps is found.
+	Weights map[Relation]float64
+	// The greatest number of relations followed, or no limit if zero
+	MaxDepth int
+}
+
+// Marks an edge or label as being at the canonical synonym of a cluster
+// rather than a particular word
+const noWord = ^uint32(0)
+
+// An edge into a cluster, from another cluster or from one of its
+// words, and for lexical relations into one of the words of the cluster
+type inEdge struct {
+	rel            Relation
+	from, fromWord uint32
+	toWord         uint32
+}
+
+// inEdges returns the edges into a cluster, indexing the edges of all
+// clusters by their target the first time it is called
+func (h *Handle) inEdges(c uint32) []inEdge {
+	h.inOnce.Do(func() {
+		h.inStart = make([]uint32, len(h.clusters)+1)
+		each := func(cb func(from uint32, e *edgeRec, fromWord uint32)) {
+			for i := range h.clusters {
+				c := &h.clusters[i]
+				for j := range h.edges(c) {
+					cb(uint32(i), &h.edgeRecs[c.edges+uint32(j)], noWord)
+				}
+				for w := c.words; w < c.words+uint32(c.nWords); w++ {
+					word := &h.wordRecs[w]
+					for j := range h.wordEdges(word) {
+						cb(uint32(i), &h.edgeRecs[word.edges+uint32(j)], w)
+					}
+				}
+			}
+		}
+		each(func(from uint32, e *edgeRec, fromWord uint32) {
+			h.inStart[e.cluster+1]++
+		})
+		for i := 1; i < len(h.inStart); i++ {
+			h.inStart[i] += h.inStart[i-1]
+		}
+		h.in = make([]inEdge, h.inStart[len(h.clusters)])
+		next := append([]uint32(nil), h.inStart...)
+		each(func(from uint32, e *edgeRec, fromWord uint32) {
+			toWord := noWord
+			if fromWord != noWord {
+				toWord = h.clusters[e.cluster].words + e.word
+			}
+			h.in[next[e.cluster]] = inEdge{rel: e.rel, from: from, fromWord: fromWord, toWord: toWord}
+			next[e.cluster]++
+		})
+	})
+	return h.in[h.inStart[c]:h.inStart[c+1]]
+}
+
+// A neighbor of a cluster, by a relation in either direction.  here and
+// there are the words at either end of a lexical relation, or noWord.
+type neighbor struct {
+	cluster     uint32
+	rel         Relation
+	forward     bool
+	here, there uint32
+}
+
+// neighbors calls cb for each cluster related to c by one of the
+// relations in r, in either direction
+func (h *Handle) neighbors(c uint32, r Relation, cb func(neighbor)) {
+	rec := &h.clusters[c]
+	for _, e := range h.edges(rec) {
+		if e.rel&r != 0 {
+			cb(neighbor{cluster: e.cluster, rel: e.rel, forward: true, here: noWord, there: noWord})
+		}
+	}
+	for w := rec.words; w < rec.words+uint32(rec.nWords); w++ {
+		for _, e := range h.wordEdges(&h.wordRecs[w]) {
+			if e.rel&r != 0 {
+				cb(neighbor{cluster: e.cluster, rel: e.rel, forward: true, here: w, there: h.clusters[e.cluster].words + e.word})
+			}
+		}
+	}
+	for _, e := range h.inEdges(c) {
+		if e.rel&r != 0 {
+			cb(neighbor{cluster: e.from, rel: e.rel, forward: false, here: e.toWord, there: e.fromWord})
+		}
+	}
+}
+
+// A label records the cheapest way found so far to reach a cluster in
+// so many steps, from one end of a path
+type pathLabel struct {
+	cost  float64
+	steps int
+	neighbor
+	prev      int // the label reached from, or -1 at the end of the path
+	dominated bool
+}
+
+// A search from one end of a path, keeping for each cluster the labels
+// which no other is both cheaper and shorter than
+type pathSearch struct {
+	labels  []pathLabel
+	byNode  map[uint32][]int
+	pending []int // labels to expand, as a heap by cost
+}
+
+func (s *pathSearch) Len() int { return len(s.pending) }
+func (s *pathSearch) Less(i, j int) bool {
+	return s.labels[s.pending[i]].cost < s.labels[s.pending[j]].cost
+}
+func (s *pathSearch) Swap(i, j int) { s.pending[i], s.pending[j] = s.pending[j], s.pending[i] }
+func (s *pathSearch) Push(x any)    { s.pending = append(s.pending, x.(int)) }
+func (s *pathSearch) Pop() any {
+	i := s.pending[len(s.pending)-1]
+	s.pending = s.pending[:len(s.pending)-1]
+	return i
+}
+
+// add adds a label unless another at the same cluster dominates it,
+// returning its position or -1
+func (s *pathSearch) add(l pathLabel) int {
+	for _, i := range s.byNode[l.cluster] {
+		if o := &s.labels[i]; !o.dominated && o.cost <= l.cost && o.steps <= l.steps {
+			return -1
+		}
+	}
+	for _, i := range s.byNode[l.cluster] {
+		if o := &s.labels[i]; o.cost >= l.cost && o.steps >= l.steps {
+			o.dominated = true
+		}
+	}
+	s.labels = append(s.labels, l)
+	i := len(s.labels) - 1
+	s.byNode[l.cluster] = append(s.byNode[l.cluster], i)
+	heap.Push(s, i)
+	return i
+}
+
+// The path between two meanings which follows relations in r, in either
+// direction, at the least cost.  Both semantic relations between synsets
+// and lexical relations between words, such as Antonym, are followed.
+// The first step is a, the last is a meaning of b's synset.  ok is false
+// if there is no such path within opts.MaxDepth steps.
+func (h *Handle) ShortestPath(a, b Lookup, r Relation, opts PathOptions) (path []Step, ok bool) {
+	weight := func(rel Relation) float64 {
+		if w, ok := opts.Weights[rel]; ok {
+			return w
+		}
+		return 1
+	}
+	within := func(steps int) bool {
+		return opts.MaxDepth <= 0 || steps <= opts.MaxDepth
+	}
+	start := func(c uint32) *pathSearch {
+		s := &pathSearch{byNode: map[uint32][]int{}}
+		s.add(pathLabel{neighbor: neighbor{cluster: c, here: noWord, there: noWord}, prev: -1})
+		return s
+	}
+	sides := [2]*pathSearch{start(a.cluster), start(b.cluster)}
+	best, meet := 0.0, [2]int{-1, -1}
+	// meets records the cheapest path through a new label of a side
+	meets := func(side, i int) {
+		l := &sides[side].labels[i]
+		for _, j := range sides[1-side].byNode[l.cluster] {
+			o := &sides[1-side].labels[j]
+			if within(l.steps+o.steps) && (meet[0] < 0 || l.cost+o.cost < best) {
+				best = l.cost + o.cost
+				meet[side], meet[1-side] = i, j
+			}
+		}
+	}
+	meets(0, 0)
+	for sides[0].Len() > 0 && sides[1].Len() > 0 {
+		cheapest := [2]float64{sides[0].labels[sides[0].pending[0]].cost, sides[1].labels[sides[1].pending[0]].cost}
+		if meet[0] >= 0 && cheapest[0]+cheapest[1] >= best {
+			break
+		}
+		side := 0
+		if cheapest[1] < cheapest[0] {
+			side = 1
+		}
+		s := sides[side]
+		i := heap.Pop(s).(int)
+		l := s.labels[i]
+		if l.dominated || !within(l.steps+1) {
+			continue
+		}
+		h.neighbors(l.cluster, r, func(n neighbor) {
+			if j := s.add(pathLabel{cost: l.cost + weight(n.rel), steps: l.steps + 1, neighbor: n, prev: i}); j >= 0 {
+				meets(side, j)
+			}
+		})
+	}
+	if meet[0] < 0 {
+		return nil, false
+	}
+
+	// the labels from a lead back from the meeting point, those from b
+	// lead on to b
+	step := func(c, word uint32) Lookup {
+		if word == noWord {
+			return h.lookup(c)
+		}
+		return h.lookupWord(word)
+	}
+	for i := meet[0]; i >= 0; i = sides[0].labels[i].prev {
+		l := &sides[0].labels[i]
+		if l.prev < 0 {
+			path = append(path, Step{Lookup: a})
+		} else {
+			path = append(path, Step{Lookup: step(l.cluster, l.there), Relation: l.rel, Reverse: !l.forward})
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	for i := meet[1]; sides[1].labels[i].prev >= 0; i = sides[1].labels[i].prev {
+		l := &sides[1].labels[i]
+		prev := &sides[1].labels[l.prev]
+		path = append(path, Step{Lookup: step(prev.cluster, l.here), Relation: l.rel, Reverse: l.forward})
+	}
+	return path, true
+}
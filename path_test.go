@@ -0,0 +1,57 @@
+package wnram
+
+import (
+	"strings"
+	"testing"
+)
+
+// describe lists the words of a path, marking relations followed
+// backwards with "<"
+func describe(path []Step) string {
+	var words []string
+	for _, s := range path {
+		if s.Reverse {
+			words = append(words, "<"+s.Word())
+		} else {
+			words = append(words, s.Word())
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func TestShortestPath(t *testing.T) {
+	needWordnet(t)
+	stroll, _ := wnInstance.Synset("01921973-v")
+	sprint, _ := wnInstance.Synset("01932532-v")
+
+	path, ok := wnInstance.ShortestPath(stroll, sprint, Hypernym, PathOptions{})
+	if got := describe(path); !ok || got != "stroll walk travel <travel rapidly <run <sprint" {
+		t.Errorf("unexpected path from stroll to sprint: %s", got)
+	}
+	for _, s := range path[1:] {
+		if s.Relation != Hypernym {
+			t.Errorf("expected only hypernyms, got %v", s.Relation)
+		}
+	}
+	if _, ok := wnInstance.ShortestPath(stroll, sprint, Hypernym, PathOptions{MaxDepth: 4}); ok {
+		t.Errorf("expected no path within 4 steps")
+	}
+
+	// hyponyms are the way down when hypernyms are costly
+	path, _ = wnInstance.ShortestPath(stroll, sprint, Hypernym|Hyponym, PathOptions{Weights: map[Relation]float64{Hypernym: 10}})
+	if got := describe(path); got != "stroll <walk <travel travel rapidly run sprint" {
+		t.Errorf("unexpected path avoiding hypernyms: %s", got)
+	}
+
+	// antonyms relate words rather than synsets
+	decrease, _ := wnInstance.Synset("00442400-v")
+	increase, _ := wnInstance.Synset("00153083-v")
+	path, ok = wnInstance.ShortestPath(decrease, increase, Antonym, PathOptions{})
+	if !ok || len(path) != 2 || path[1].Relation != Antonym || path[1].Word() != "increase" {
+		t.Errorf("unexpected path from decrease to increase: %s", describe(path))
+	}
+
+	if path, ok := wnInstance.ShortestPath(stroll, stroll, Hypernym, PathOptions{}); !ok || len(path) != 1 {
+		t.Errorf("expected a path of one step to the same synset, got %s", describe(path))
+	}
+}
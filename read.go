@@ -2,15 +2,20 @@ package wnram
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
 	"io"
-	"os"
+	"io/fs"
+	"strings"
 )
 
 // InPlaceReadLine scans a file and invoke the provided callback for
 // every line read.  Because scanning a file for newline delimiters is
 // an incredibly cheap operation, the overhead of multi-thread
 // communication can be slower than processing on a single thread when
-// the per line computational cost is low.
+// the per line computational cost is low.  Large files are instead
+// split into chunks of many lines, see eachLine.
 func inPlaceReadLine(s io.Reader, cb func([]byte, int64, int64) error) error {
 	const bufSize = 8396800 // 8 meg
 	reader := bufio.NewReaderSize(s, bufSize)
@@ -37,11 +42,66 @@ func inPlaceReadLine(s io.Reader, cb func([]byte, int64, int64) error) error {
 	return nil
 }
 
-func inPlaceReadLineFromPath(filePath string, cb func([]byte, int64, int64) error) error {
-	f, err := os.Open(filePath)
+// eachLine invokes the callback for every line of a chunk of a file, as
+// inPlaceReadLine does, given the number and offset of its first line.
+func eachLine(data []byte, count, offset int64, cb func([]byte, int64, int64) error) error {
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, '\n')
+		if n < 0 {
+			return cb(data, count, offset)
+		}
+		if err := cb(data[:n], count, offset); err != nil {
+			return err
+		}
+		data = data[n+1:]
+		offset += int64(n + 1)
+		count++
+	}
+	return nil
+}
+
+// A countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f fs.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// openDecompressed opens a file, decompressing it if the name ends in
+// .gz or .tgz, and returns the name of its contents, e.g. data.noun for
+// data.noun.gz
+func openDecompressed(fsys fs.FS, name string) (io.ReadCloser, string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	var contents string
+	if strings.HasSuffix(name, ".gz") {
+		contents = strings.TrimSuffix(name, ".gz")
+	} else if strings.HasSuffix(name, ".tgz") {
+		contents = strings.TrimSuffix(name, ".tgz") + ".tar"
+	} else {
+		return f, name, nil
+	}
+	r, err := gzip.NewReader(f)
 	if err != nil {
-		return err
+		f.Close()
+		return nil, "", fmt.Errorf("%s: %w", name, err)
 	}
-	defer f.Close()
-	return inPlaceReadLine(f, cb)
+	return gzipFile{r, f}, contents, nil
 }
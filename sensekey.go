@@ -0,0 +1,151 @@
+package wnram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Identifies a single word within a cluster
+type senseRef struct {
+	cluster *cluster
+	word    int
+}
+
+// ssType returns the synset type digit used in sense keys
+func ssType(pos PartOfSpeech, satellite bool) int {
+	if satellite {
+		return 5
+	}
+	switch pos {
+	case Noun:
+		return 1
+	case Verb:
+		return 2
+	case Adjective:
+		return 3
+	case Adverb:
+		return 4
+	}
+	return 0
+}
+
+// senseKeyPOS returns the part of speech encoded in a sense key
+func senseKeyPOS(key string) (PartOfSpeech, bool) {
+	i := strings.IndexByte(key, '%')
+	if i < 0 || i+1 >= len(key) {
+		return 0, false
+	}
+	switch key[i+1] {
+	case '1':
+		return Noun, true
+	case '2':
+		return Verb, true
+	case '3', '5':
+		return Adjective, true
+	case '4':
+		return Adverb, true
+	}
+	return 0, false
+}
+
+// head returns the head synset of an adjective satellite, which the
+// satellite points to with its SimilarTo relation.
+func (c *cluster) head() *cluster {
+	if !c.satellite {
+		return nil
+	}
+	for _, r := range c.relations {
+		if r.rel == SimilarTo {
+			return r.target
+		}
+	}
+	return nil
+}
+
+// head returns the head synset of an adjective satellite
+func (h *Handle) head(c uint32) (uint32, bool) {
+	if !h.clusters[c].satellite {
+		return 0, false
+	}
+	for _, r := range h.edges(&h.clusters[c]) {
+		if r.rel == SimilarTo {
+			return r.cluster, true
+		}
+	}
+	return 0, false
+}
+
+func senseKeyLemma(w string) string {
+	return strings.ToLower(strings.Replace(w, " ", "_", -1))
+}
+
+// formatSenseKey builds a sense key, see senseidx(5WN).  The head word
+// is only given for adjective satellites.
+func formatSenseKey(lemma string, ssType int, lexFile, lexID uint8, headWord string, headID uint8) string {
+	var id string
+	if headWord != "" {
+		id = fmt.Sprintf("%02d", headID)
+	}
+	return fmt.Sprintf("%s%%%d:%02d:%02d:%s:%s", senseKeyLemma(lemma), ssType, lexFile, lexID, senseKeyLemma(headWord), id)
+}
+
+// senseKey computes the sense key of the i'th word in the cluster
+func (c *cluster) senseKey(i int) string {
+	var headWord string
+	var headID uint8
+	if h := c.head(); h != nil && len(h.words) > 0 {
+		headWord, headID = h.words[0].word, h.words[0].sense
+	}
+	return formatSenseKey(c.words[i].word, ssType(c.pos, c.satellite), c.lexFile, c.words[i].sense, headWord, headID)
+}
+
+// senseKey computes the sense key of a word
+func (h *Handle) senseKey(w *wordRec) string {
+	c := &h.clusters[w.cluster]
+	var headWord string
+	var headID uint8
+	if head, ok := h.head(w.cluster); ok {
+		first := &h.wordRecs[h.clusters[head].words]
+		headWord, headID = h.str(first.word), first.sense
+	}
+	return formatSenseKey(h.str(w.word), ssType(c.pos, c.satellite), c.lexFile, w.sense, headWord, headID)
+}
+
+// The sense key of this word in this meaning, e.g. "dog%1:05:00::".
+// Sense keys identify word senses across WordNet versions and are
+// used by sense tagged corpora such as SemCor.
+func (w *Lookup) SenseKey() string {
+	word := w.wordRec()
+	if word == nil {
+		return ""
+	}
+	return w.h.senseKey(word)
+}
+
+// Find the word and meaning identified by a sense key
+func (h *Handle) LookupSenseKey(key string) (Lookup, bool) {
+	key = strings.ToLower(key)
+	i := strings.IndexByte(key, '%')
+	if i < 0 {
+		return Lookup{}, false
+	}
+	// sense keys are computed from the words of the lemma's clusters,
+	// or else were kept from index.sense
+	lemma := strings.Replace(key[:i], "_", " ", -1)
+	for _, c := range h.lookupIndex(lemma) {
+		words := h.words(&h.clusters[c])
+		for j := range words {
+			if normalize(h.str(words[j].word)) == lemma && h.senseKey(&words[j]) == key {
+				return h.lookupWord(h.clusters[c].words + uint32(j)), true
+			}
+		}
+	}
+	n := sort.Search(len(h.senseKeys), func(n int) bool {
+		return h.str(h.senseKeys[n].key) >= key
+	})
+	if n < len(h.senseKeys) && h.str(h.senseKeys[n].key) == key {
+		return h.lookupWord(h.senseKeys[n].word), true
+	}
+	return Lookup{}, false
+}
@@ -0,0 +1,43 @@
+package wnram
+
+import "testing"
+
+func TestSenseKey(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "yummy", POS: []PartOfSpeech{Adjective}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected one synonym cluster for yummy, got %d", len(found))
+	}
+	if key := found[0].SenseKey(); key != "yummy%5:00:00:tasty:00" {
+		t.Errorf("unexpected sense key for yummy: %s", key)
+	}
+
+	l, ok := wnInstance.LookupSenseKey("delicious%5:00:00:tasty:00")
+	if !ok {
+		t.Fatalf("can't find sense key for delicious")
+	}
+	if l.Word() != "delicious" || !setContains(l.Synonyms(), []string{"yummy"}) {
+		t.Errorf("sense key lookup found the wrong synset: %s", l.DumpStr())
+	}
+
+	if _, ok := wnInstance.LookupSenseKey("yummy%5:00:00:tasty:99"); ok {
+		t.Errorf("found a bogus sense key")
+	}
+}
+
+func TestSenseKeyRoundTrip(t *testing.T) {
+	needWordnet(t)
+	wnInstance.Iterate(PartOfSpeechList{Adverb, Verb}, func(l Lookup) error {
+		key := l.SenseKey()
+		found, ok := wnInstance.LookupSenseKey(key)
+		if !ok {
+			t.Fatalf("can't find sense key %s", key)
+		} else if found.cluster != l.cluster || found.Word() != l.Word() {
+			t.Fatalf("sense key %s found %s, expected %s", key, found.String(), l.String())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,275 @@
+package wnram
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Similarity measures score how alike two meanings are by their
+// positions in the hypernym taxonomy, higher scores being more similar.
+// They follow NLTK, and give the same scores for the same data.
+type Measure uint8
+
+const (
+	// 1/(d+1), where d is the length of the shortest path between the
+	// synsets through a common hypernym.
+	PathSimilarity Measure = iota
+	// Leacock-Chodorow similarity, -log((d+1)/2D), where D is the
+	// greatest depth of the taxonomy of their part of speech.  Only
+	// synsets of the same part of speech may be compared.
+	LCHSimilarity
+	// Wu-Palmer similarity, 2S/(d1+d2+2S), where S is the depth of the
+	// deepest common hypernym counting from 1, and d1 and d2 are the
+	// distances to it.
+	WUPSimilarity
+	// Resnik similarity, the information content of the most
+	// informative common hypernym, see SimilarityOptions.IC.
+	ResnikSimilarity
+	// Lin similarity, 2IC(lcs)/(IC(a)+IC(b)), where lcs is the most
+	// informative common hypernym.
+	LinSimilarity
+	// Jiang-Conrath similarity, 1/(IC(a)+IC(b)-2IC(lcs)).
+	JCNSimilarity
+)
+
+func (m Measure) String() string {
+	switch m {
+	case PathSimilarity:
+		return "path"
+	case LCHSimilarity:
+		return "lch"
+	case WUPSimilarity:
+		return "wup"
+	case ResnikSimilarity:
+		return "res"
+	case LinSimilarity:
+		return "lin"
+	case JCNSimilarity:
+		return "jcn"
+	}
+	return "unknown"
+}
+
+// Options for the similarity measures
+type SimilarityOptions struct {
+	// Nouns share a single root, but verbs have hundreds, and adjectives
+	// and adverbs have no hypernyms at all.  By default those are joined
+	// under a simulated root as in NLTK, so that any two synsets may be
+	// compared.  Set this to compare only synsets with a common hypernym.
+	NoSimulatedRoot bool
+	// The information content of synsets, required by the Resnik, Lin
+	// and Jiang-Conrath measures, which compare only synsets of the same
+	// part of speech.  See LoadInformationContent.
+	IC *InformationContent
+	// The parts of speech of the senses compared by WordSimilarity, or
+	// all.
+	POS PartOfSpeechList
+}
+
+// simulatedRoot stands for the root simulated above the roots of a
+// taxonomy, in place of a cluster
+const simulatedRoot = ^uint32(0)
+
+// needsRoot reports whether synsets of a part of speech are joined under
+// a simulated root, which NLTK does for all but nouns
+func needsRoot(pos PartOfSpeech) bool {
+	return pos != Noun
+}
+
+// distances returns the length of the shortest path from a cluster to
+// each of its hypernyms, and with simulate to the simulated root, which
+// NLTK places one beyond the furthest hypernym.
+func (h *Handle) distances(c uint32, simulate bool) map[uint32]int {
+	if c == simulatedRoot {
+		return map[uint32]int{c: 0}
+	}
+	dist := map[uint32]int{c: 0}
+	level := []uint32{c}
+	max := 0
+	for d := 1; len(level) > 0; d++ {
+		var next []uint32
+		for _, c := range level {
+			for _, e := range h.edges(&h.clusters[c]) {
+				if _, ok := dist[e.cluster]; e.rel&hypernymRelations != 0 && !ok {
+					dist[e.cluster] = d
+					next = append(next, e.cluster)
+					max = d
+				}
+			}
+		}
+		level = next
+	}
+	if simulate {
+		dist[simulatedRoot] = max + 1
+	}
+	return dist
+}
+
+// distance returns the length of the shortest path between two clusters
+// through a common hypernym
+func (h *Handle) distance(a, b uint32, simulate bool) (int, bool) {
+	if a == b {
+		return 0, true
+	}
+	da, db := h.distances(a, simulate), h.distances(b, simulate)
+	best, ok := 0, false
+	for c, d := range da {
+		if d2, found := db[c]; found && (!ok || d+d2 < best) {
+			best, ok = d+d2, true
+		}
+	}
+	return best, ok
+}
+
+// taxonomyDepth returns the greatest depth of the synsets of a part of
+// speech, computed once
+func (h *Handle) taxonomyDepth(pos PartOfSpeech) int {
+	h.depthsOnce.Do(func() {
+		depths := map[uint32]int{}
+		for i := range h.clusters {
+			c := &h.clusters[i]
+			if d := h.maxDepth(uint32(i), hypernymRelations, depths); d > h.depths[c.pos] {
+				h.depths[c.pos] = d
+			}
+		}
+	})
+	return h.depths[pos]
+}
+
+// nltkName returns the name NLTK gives a synset, e.g. "dog.n.01", by
+// which it orders hypernyms of the same depth
+func (h *Handle) nltkName(c uint32) string {
+	if c == simulatedRoot {
+		return "*ROOT*"
+	}
+	l := h.lookup(c)
+	lemma := strings.ToLower(strings.Replace(l.Word(), " ", "_", -1))
+	return fmt.Sprintf("%s.%c.%02d", lemma, h.clusters[c].posLetter(), l.SenseNumber())
+}
+
+// subsumer returns the common hypernym of a and b which is furthest from
+// a root by its shortest path, as NLTK's Wu-Palmer similarity uses
+func (h *Handle) subsumer(a, b uint32, simulate bool) (uint32, bool) {
+	common := h.ancestors(a, hypernymRelations)
+	other := h.ancestors(b, hypernymRelations)
+	var deepest []uint32
+	best := 0
+	if simulate {
+		deepest = append(deepest, simulatedRoot)
+	}
+	for c := range common {
+		if !other[c] {
+			continue
+		}
+		l := h.lookup(c)
+		switch d := l.MinDepth(); {
+		case d > best:
+			deepest, best = []uint32{c}, d
+		case d == best:
+			deepest = append(deepest, c)
+		}
+	}
+	if len(deepest) == 0 {
+		return 0, false
+	}
+	for _, c := range deepest {
+		if c == a {
+			return a, true
+		}
+	}
+	sort.Slice(deepest, func(i, j int) bool {
+		return h.nltkName(deepest[i]) < h.nltkName(deepest[j])
+	})
+	return deepest[0], true
+}
+
+// Score the similarity of two synsets.  ok is false when the measure
+// does not apply to them, e.g. when they have no common hypernym.
+func (h *Handle) Similarity(a, b Lookup, m Measure, opts SimilarityOptions) (score float64, ok bool) {
+	simulate := !opts.NoSimulatedRoot && (needsRoot(a.POS()) || needsRoot(b.POS()))
+	switch m {
+	case PathSimilarity:
+		d, ok := h.distance(a.cluster, b.cluster, simulate)
+		if !ok {
+			return 0, false
+		}
+		return 1 / float64(d+1), true
+	case LCHSimilarity:
+		if a.c().posLetter() != b.c().posLetter() {
+			return 0, false
+		}
+		// NLTK counts the simulated root in the depth whether or not
+		// it is used
+		depth := h.taxonomyDepth(a.POS())
+		if needsRoot(a.POS()) {
+			depth++
+		}
+		simulate = simulate && needsRoot(a.POS())
+		d, ok := h.distance(a.cluster, b.cluster, simulate)
+		if !ok || depth == 0 {
+			return 0, false
+		}
+		return -math.Log(float64(d+1) / float64(2*depth)), true
+	case WUPSimilarity:
+		s, ok := h.subsumer(a.cluster, b.cluster, simulate)
+		if !ok {
+			return 0, false
+		}
+		depth := 1
+		if s != simulatedRoot {
+			depth = h.maxDepth(s, hypernymRelations, map[uint32]int{}) + 1
+		}
+		d1, ok1 := h.distance(a.cluster, s, simulate)
+		d2, ok2 := h.distance(b.cluster, s, simulate)
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		return float64(2*depth) / float64(d1+d2+2*depth), true
+	case ResnikSimilarity:
+		_, _, lcs, ok := h.icScores(a, b, opts.IC)
+		return lcs, ok
+	case LinSimilarity:
+		icA, icB, lcs, ok := h.icScores(a, b, opts.IC)
+		if !ok || icA+icB == 0 {
+			return 0, false
+		}
+		return 2 * lcs / (icA + icB), true
+	case JCNSimilarity:
+		icA, icB, lcs, ok := h.icScores(a, b, opts.IC)
+		switch {
+		case !ok:
+			return 0, false
+		case a.cluster == b.cluster:
+			return infiniteIC, true
+		case icA == 0 || icB == 0:
+			return 0, true
+		case icA+icB == 2*lcs:
+			return infiniteIC, true
+		}
+		return 1 / (icA + icB - 2*lcs), true
+	}
+	return 0, false
+}
+
+// The greatest similarity of any senses of two words, and those senses.
+// ok is false if either word is not found, or no senses may be compared.
+func (h *Handle) WordSimilarity(a, b string, m Measure, opts SimilarityOptions) (score float64, senses [2]Lookup, ok bool) {
+	as, err := h.Lookup(Criteria{Matching: a, POS: opts.POS})
+	if err != nil {
+		return 0, senses, false
+	}
+	bs, err := h.Lookup(Criteria{Matching: b, POS: opts.POS})
+	if err != nil {
+		return 0, senses, false
+	}
+	for _, x := range as {
+		for _, y := range bs {
+			if s, found := h.Similarity(x, y, m, opts); found && (!ok || s > score) {
+				score, senses, ok = s, [2]Lookup{x, y}, true
+			}
+		}
+	}
+	return score, senses, ok
+}
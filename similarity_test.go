@@ -0,0 +1,104 @@
+package wnram
+
+import (
+	"math"
+	"testing"
+	"testing/fstest"
+)
+
+// A small noun taxonomy, 4 deep from dog and cat to entity
+var taxonomyFS = fstest.MapFS{
+	"data.noun": {Data: []byte(
+		"00000010 03 n 01 entity 0 000 | that which exists\n" +
+			"00000020 05 n 01 animal 0 001 @ 00000010 n 0000 | a living organism\n" +
+			"00000030 05 n 01 carnivore 0 001 @ 00000020 n 0000 | a flesh-eating animal\n" +
+			"00000040 05 n 01 canine 0 001 @ 00000030 n 0000 | of the family Canidae\n" +
+			"00000050 05 n 01 feline 0 001 @ 00000030 n 0000 | of the family Felidae\n" +
+			"00000060 05 n 01 dog 0 001 @ 00000040 n 0000 | a domesticated canine\n" +
+			"00000070 05 n 01 cat 0 001 @ 00000050 n 0000 | a domesticated feline\n")},
+}
+
+// The scores of pairs of the fixture, by NLTK's formulas: dog and cat
+// are 4 apart through carnivore, at depth 2, and dog and animal 3 apart
+var similarityTests = []struct {
+	a, b           string
+	path, lch, wup float64
+}{
+	{"00000060-n", "00000070-n", 1.0 / 5, -math.Log(5.0 / 8), 2.0 * 3 / (2 + 2 + 2*3)},
+	{"00000060-n", "00000020-n", 1.0 / 4, -math.Log(4.0 / 8), 2.0 * 2 / (3 + 0 + 2*2)},
+}
+
+func TestSimilarity(t *testing.T) {
+	h, err := NewFS(taxonomyFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for _, test := range similarityTests {
+		a, err := h.Synset(test.a)
+		if err != nil {
+			t.Errorf("%s", err)
+			continue
+		}
+		b, err := h.Synset(test.b)
+		if err != nil {
+			t.Errorf("%s", err)
+			continue
+		}
+		for m, want := range map[Measure]float64{PathSimilarity: test.path, LCHSimilarity: test.lch, WUPSimilarity: test.wup} {
+			got, ok := h.Similarity(a, b, m, SimilarityOptions{})
+			if !ok || math.Abs(got-want) > 1e-9 {
+				t.Errorf("expected %s similarity of %s and %s to be %v, got %v", m, a.Word(), b.Word(), want, got)
+			}
+		}
+	}
+}
+
+// hit.v.01 and slap.v.01 of the bundled WordNet 3.1 have no common
+// hypernym: hit is a kind of propel, a kind of move, and slap a kind of
+// strike, a kind of touch.  Through the simulated root they are 6 apart,
+// which is 1 deep, and the verb taxonomy is 12 deep, or 13 with the
+// root.  NLTK's howto gives the same scores for them in WordNet 3.0.
+func TestVerbSimilarity(t *testing.T) {
+	needWordnet(t, Verb)
+	hit, err := wnInstance.Synset("01407698-v")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	slap, err := wnInstance.Synset("01419525-v")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if d := wnInstance.taxonomyDepth(Verb); d != 12 {
+		t.Errorf("expected the verb taxonomy to be 12 deep, got %d", d)
+	}
+	for m, want := range map[Measure]float64{
+		PathSimilarity: 1.0 / 7,
+		LCHSimilarity:  -math.Log(7.0 / 26),
+		WUPSimilarity:  2.0 * 1 / (3 + 3 + 2*1),
+	} {
+		got, ok := wnInstance.Similarity(hit, slap, m, SimilarityOptions{})
+		if !ok || math.Abs(got-want) > 1e-9 {
+			t.Errorf("expected %s similarity of hit and slap to be %v, got %v", m, want, got)
+		}
+	}
+	if _, ok := wnInstance.Similarity(hit, slap, PathSimilarity, SimilarityOptions{NoSimulatedRoot: true}); ok {
+		t.Errorf("expected no path similarity without a simulated root")
+	}
+	if s, ok := wnInstance.Similarity(hit, hit, PathSimilarity, SimilarityOptions{}); !ok || s != 1 {
+		t.Errorf("expected a synset to be identical to itself, got %v", s)
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	needWordnet(t)
+	score, senses, ok := wnInstance.WordSimilarity("hit", "slap", WUPSimilarity, SimilarityOptions{POS: PartOfSpeechList{Verb}})
+	if !ok || score < 0.25 {
+		t.Fatalf("expected hit and slap to be at least 0.25 similar, got %v", score)
+	}
+	if s, _ := wnInstance.Similarity(senses[0], senses[1], WUPSimilarity, SimilarityOptions{}); s != score {
+		t.Errorf("expected the score of the senses found, %v, got %v", score, s)
+	}
+	if _, _, ok := wnInstance.WordSimilarity("hit", "xyzzy", WUPSimilarity, SimilarityOptions{}); ok {
+		t.Errorf("expected no similarity to an unknown word")
+	}
+}
@@ -0,0 +1,382 @@
+package wnram
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"unsafe"
+)
+
+// Snapshots are an image of the tables of a Handle, which may be used
+// in place rather than parsed (see tables.go).  A snapshot is:
+//
+//	magic, version, number of sections, source checksum, section table,
+//	sections, crc32
+//
+// where the source checksum is that of the files the tables were loaded
+// from (see SourceChecksum) padded with zeros to 64 bytes, the section
+// table gives the offset and length of each section as 64 bit integers,
+// and each section is the raw memory of a table, padded to a multiple of
+// 8 bytes.  The records contain only 32 bit and
+// smaller integers, so their layout is the same on all platforms of the
+// same byte order; snapshots are always little endian.
+const snapshotMagic = "WNRAMSNP"
+
+// Incremented whenever the encoding changes, older snapshots are then
+// rejected and must be rebuilt.
+const snapshotVersion = 3
+
+// The size of the header before the section table, the source checksum
+// being a hex encoded sha256
+const (
+	checksumSize       = 64
+	snapshotHeaderSize = 16 + checksumSize
+)
+
+// A table of a Handle, as stored in a snapshot
+type section struct {
+	bytes func() []byte
+	set   func(data []byte) error
+}
+
+// rawBytes returns the memory of a table
+func rawBytes[T any](table []T) []byte {
+	if len(table) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&table[0])), len(table)*int(unsafe.Sizeof(table[0])))
+}
+
+func tableSection[T any](table *[]T) section {
+	return section{
+		bytes: func() []byte { return rawBytes(*table) },
+		set: func(data []byte) error {
+			var zero T
+			size := int(unsafe.Sizeof(zero))
+			if len(data)%size != 0 {
+				return fmt.Errorf("snapshot section of %d bytes does not hold %d byte records", len(data), size)
+			}
+			*table = nil
+			if len(data) > 0 {
+				*table = unsafe.Slice((*T)(unsafe.Pointer(&data[0])), len(data)/size)
+			}
+			return nil
+		},
+	}
+}
+
+func stringSection(s *string) section {
+	return section{
+		bytes: func() []byte { return unsafe.Slice(unsafe.StringData(*s), len(*s)) },
+		set: func(data []byte) error {
+			*s = ""
+			if len(data) > 0 {
+				*s = unsafe.String(&data[0], len(data))
+			}
+			return nil
+		},
+	}
+}
+
+// sections lists the tables of a Handle in snapshot order
+func (h *Handle) sections() []section {
+	return []section{
+		stringSection(&h.text),
+		tableSection(&h.clusters),
+		tableSection(&h.wordRecs),
+		tableSection(&h.edgeRecs),
+		tableSection(&h.frames),
+		tableSection(&h.sentences),
+		tableSection(&h.targets),
+		tableSection(&h.lists),
+		tableSection(&h.index),
+		tableSection(&h.exceptions),
+		tableSection(&h.tagCounts),
+		tableSection(&h.senseKeys),
+		tableSection(&h.verbSentences),
+		tableSection(&h.lexNames),
+		tableSection(&h.languages),
+		tableSection(&h.langKeys),
+		tableSection(&h.translations),
+	}
+}
+
+func align(n uint64) uint64 {
+	return (n + 7) &^ 7
+}
+
+// aligned returns data, copied if it does not start on an 8 byte
+// boundary so that its records may be used in place
+func aligned(data []byte) []byte {
+	if uintptr(unsafe.Pointer(unsafe.SliceData(data)))%8 == 0 {
+		return data
+	}
+	buf := make([]uint64, align(uint64(len(data)))/8)
+	b := rawBytes(buf)[:len(data)]
+	copy(b, data)
+	return b
+}
+
+func littleEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
+
+// The checksum of the WordNet files this database was loaded from, as
+// recorded in snapshots.  See SourceChecksum.
+func (h *Handle) SourceChecksum() string {
+	return h.checksum
+}
+
+// Compute the checksum of the WordNet files in a directory, without
+// loading them.  Only the files which NewFSWithOptions would read with
+// the same options are included, so that a database loaded with
+// Options.POS has the checksum computed with it.  A snapshot whose
+// Handle.SourceChecksum differs was built from other files and should be
+// rebuilt.
+func SourceChecksum(fsys fs.FS, root string, opts Options) (string, error) {
+	ld := newLoader()
+	ld.checksumOnly = true
+	ld.pos = opts.POS
+	if opts.Context != nil {
+		ld.ctx = opts.Context
+	}
+	if err := ld.loadDir(fsys, root); err != nil {
+		return "", err
+	}
+	return ld.checksum(), nil
+}
+
+// Write a snapshot of the database, which may be loaded with
+// LoadSnapshot or NewMapped.
+func (h *Handle) WriteSnapshot(w io.Writer) error {
+	if !littleEndian() {
+		return fmt.Errorf("snapshots are not supported on big endian platforms")
+	}
+	sections := h.sections()
+	if len(h.checksum) > checksumSize {
+		return fmt.Errorf("source checksum of %d bytes, expected at most %d", len(h.checksum), checksumSize)
+	}
+	header := make([]byte, snapshotHeaderSize+16*len(sections))
+	copy(header, snapshotMagic)
+	binary.LittleEndian.PutUint32(header[8:], snapshotVersion)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(sections)))
+	copy(header[16:], h.checksum)
+	bodies := make([][]byte, len(sections))
+	offset := uint64(len(header))
+	for i, s := range sections {
+		bodies[i] = s.bytes()
+		binary.LittleEndian.PutUint64(header[snapshotHeaderSize+16*i:], offset)
+		binary.LittleEndian.PutUint64(header[snapshotHeaderSize+8+16*i:], uint64(len(bodies[i])))
+		offset = align(offset + uint64(len(bodies[i])))
+	}
+
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+	bw.Write(header)
+	var pad [8]byte
+	for _, b := range bodies {
+		bw.Write(b)
+		bw.Write(pad[:align(uint64(len(b)))-uint64(len(b))])
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, crc.Sum32())
+}
+
+// fromImage uses the tables of a snapshot in place.  The crc is only
+// checked if verify is set, as that reads the whole snapshot.  Unless
+// checksum is empty, the snapshot must have been built from source files
+// with that checksum.
+func fromImage(data []byte, verify bool, checksum string) (*Handle, error) {
+	if !littleEndian() {
+		return nil, fmt.Errorf("snapshots are not supported on big endian platforms")
+	}
+	if !bytes.HasPrefix(data, []byte(snapshotMagic)) {
+		return nil, fmt.Errorf("not a wnram snapshot")
+	}
+	if len(data) < 16 {
+		return nil, fmt.Errorf("snapshot truncated")
+	}
+	if v := binary.LittleEndian.Uint32(data[8:]); v != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected %d", v, snapshotVersion)
+	}
+	var h Handle
+	sections := h.sections()
+	if n := binary.LittleEndian.Uint32(data[12:]); n != uint32(len(sections)) {
+		return nil, fmt.Errorf("snapshot has %d sections, expected %d", n, len(sections))
+	}
+	end := uint64(snapshotHeaderSize + 16*len(sections))
+	if uint64(len(data)) < end+4 {
+		return nil, fmt.Errorf("snapshot truncated")
+	}
+	h.checksum = string(bytes.TrimRight(data[16:snapshotHeaderSize], "\x00"))
+	if checksum != "" && h.checksum != checksum {
+		return nil, fmt.Errorf("stale snapshot: built from source files with checksum %s, expected %s", h.checksum, checksum)
+	}
+	body, trailer := data[:len(data)-4], data[len(data)-4:]
+	if verify && crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(trailer) {
+		return nil, fmt.Errorf("snapshot checksum mismatch")
+	}
+	for i, s := range sections {
+		offset := binary.LittleEndian.Uint64(data[snapshotHeaderSize+16*i:])
+		length := binary.LittleEndian.Uint64(data[snapshotHeaderSize+8+16*i:])
+		if offset != end || length > uint64(len(body))-offset {
+			return nil, fmt.Errorf("snapshot truncated")
+		}
+		if err := s.set(body[offset : offset+length]); err != nil {
+			return nil, err
+		}
+		end = align(offset + length)
+	}
+	if end > uint64(len(body)) {
+		return nil, fmt.Errorf("snapshot truncated")
+	} else if end != uint64(len(body)) {
+		return nil, fmt.Errorf("snapshot has %d trailing bytes", uint64(len(body))-end)
+	}
+	if err := h.validate(); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot: %s", err)
+	}
+	return &h, nil
+}
+
+// validate checks that every position in the tables is within the table
+// it refers to, and every string within the text, so that a corrupt
+// snapshot cannot cause out of range accesses.  It visits each record
+// once.
+func (h *Handle) validate() error {
+	span := func(first, n uint32, length int) bool {
+		return uint64(first)+uint64(n) <= uint64(length)
+	}
+	text := func(s str) bool {
+		return span(s.off, s.n, len(h.text))
+	}
+	for i := range h.clusters {
+		c := &h.clusters[i]
+		switch {
+		case c.pos >= AdjectiveSatellite:
+			return fmt.Errorf("synset %d has part of speech %d", i, c.pos)
+		case !text(c.gloss) || !text(c.ili):
+			return fmt.Errorf("synset %d has a string out of range", i)
+		case c.nWords == 0:
+			return fmt.Errorf("synset %d has no words", i)
+		case !span(c.words, uint32(c.nWords), len(h.wordRecs)):
+			return fmt.Errorf("synset %d has words out of range", i)
+		case !span(c.edges, uint32(c.nEdges), len(h.edgeRecs)):
+			return fmt.Errorf("synset %d has relations out of range", i)
+		case !span(c.frames, uint32(c.nFrames), len(h.frames)):
+			return fmt.Errorf("synset %d has verb frames out of range", i)
+		}
+	}
+	for i := range h.wordRecs {
+		w := &h.wordRecs[i]
+		switch {
+		case !text(w.word):
+			return fmt.Errorf("word %d has a string out of range", i)
+		case int(w.cluster) >= len(h.clusters):
+			return fmt.Errorf("word %d has synset %d out of range", i, w.cluster)
+		case !span(w.edges, uint32(w.nEdges), len(h.edgeRecs)):
+			return fmt.Errorf("word %d has relations out of range", i)
+		case !span(w.frames, uint32(w.nFrames), len(h.frames)):
+			return fmt.Errorf("word %d has verb frames out of range", i)
+		case !span(w.sentences, uint32(w.nSentences), len(h.sentences)):
+			return fmt.Errorf("word %d has example sentences out of range", i)
+		}
+		// lexical relations refer to a word of their target
+		for j, e := range h.wordEdges(w) {
+			if int(e.cluster) < len(h.clusters) && e.word >= uint32(h.clusters[e.cluster].nWords) {
+				return fmt.Errorf("relation %d of word %d has word %d out of range", j, i, e.word)
+			}
+		}
+	}
+	for i, e := range h.edgeRecs {
+		if int(e.cluster) >= len(h.clusters) {
+			return fmt.Errorf("relation %d has synset %d out of range", i, e.cluster)
+		}
+	}
+	for i, t := range h.targets {
+		if int(t) >= len(h.clusters) {
+			return fmt.Errorf("index target %d has synset %d out of range", i, t)
+		}
+	}
+	for i, s := range h.lists {
+		if !text(s) {
+			return fmt.Errorf("list entry %d has a string out of range", i)
+		}
+	}
+	keys := func(table []keyRec, name string) error {
+		for i, k := range table {
+			if !text(k.key) || !span(k.first, k.n, len(h.targets)) {
+				return fmt.Errorf("%s entry %d out of range", name, i)
+			}
+		}
+		return nil
+	}
+	if err := keys(h.index, "index"); err != nil {
+		return err
+	}
+	if err := keys(h.langKeys, "translation index"); err != nil {
+		return err
+	}
+	for i, e := range h.exceptions {
+		if e.pos >= uint32(AdjectiveSatellite) || !text(e.key) || !span(e.first, e.n, len(h.lists)) {
+			return fmt.Errorf("exception %d out of range", i)
+		}
+	}
+	for i, t := range h.tagCounts {
+		if t.pos >= uint32(AdjectiveSatellite) || !text(t.lemma) {
+			return fmt.Errorf("tag count %d out of range", i)
+		}
+	}
+	for i, k := range h.senseKeys {
+		if !text(k.key) || int(k.word) >= len(h.wordRecs) {
+			return fmt.Errorf("sense key %d out of range", i)
+		}
+	}
+	for i, s := range h.verbSentences {
+		if !text(s) {
+			return fmt.Errorf("verb sentence %d has a string out of range", i)
+		}
+	}
+	for i, s := range h.lexNames {
+		if !text(s) {
+			return fmt.Errorf("lexicographer file name %d out of range", i)
+		}
+	}
+	for i, l := range h.languages {
+		if !text(l.code) || !span(l.keys, l.nKeys, len(h.langKeys)) || !span(l.translations, l.nTrans, len(h.translations)) {
+			return fmt.Errorf("language %d out of range", i)
+		}
+	}
+	for i, t := range h.translations {
+		if int(t.cluster) >= len(h.clusters) || !span(t.lemmas, t.nLemmas, len(h.lists)) ||
+			!span(t.definitions, t.nDefinitions, len(h.lists)) || !span(t.examples, t.nExamples, len(h.lists)) {
+			return fmt.Errorf("translation %d out of range", i)
+		}
+	}
+	return nil
+}
+
+// Initialize a new in-ram WordNet database from a snapshot written by
+// Handle.WriteSnapshot.  Snapshots written by other versions of this
+// package, or which are corrupt, are rejected.  The snapshot may be
+// stale, see LoadSnapshotChecked.
+func LoadSnapshot(r io.Reader) (*Handle, error) {
+	return LoadSnapshotChecked(r, "")
+}
+
+// LoadSnapshot, rejecting a snapshot unless it was built from source
+// files with the given checksum, e.g. computed by SourceChecksum on the
+// files it is meant to stand for.  An empty checksum accepts any.
+func LoadSnapshotChecked(r io.Reader, checksum string) (*Handle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return fromImage(aligned(data), true, checksum)
+}
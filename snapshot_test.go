@@ -0,0 +1,167 @@
+package wnram
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSnapshot(t *testing.T) {
+	needWordnet(t)
+	var buf bytes.Buffer
+	if err := wnInstance.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("%s", err)
+	}
+	h, err := LoadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if h.SourceChecksum() != wnInstance.SourceChecksum() {
+		t.Errorf("expected checksum %s, got %s", wnInstance.SourceChecksum(), h.SourceChecksum())
+	}
+
+	// the loaded database writes an identical snapshot
+	var again bytes.Buffer
+	if err := h.WriteSnapshot(&again); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Errorf("snapshot of a loaded snapshot differs")
+	}
+
+	for _, crit := range []Criteria{
+		{Matching: "good"},
+		{Matching: "yummy", POS: []PartOfSpeech{Adjective}},
+		{Matching: "ran", Morphology: true},
+	} {
+		want, _ := wnInstance.Lookup(crit)
+		got, _ := h.Lookup(crit)
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d results, got %d", crit.Matching, len(want), len(got))
+		}
+		for i := range got {
+			if got[i].ID() != want[i].ID() || got[i].DumpStr() != want[i].DumpStr() ||
+				got[i].SenseKey() != want[i].SenseKey() || got[i].SenseNumber() != want[i].SenseNumber() ||
+				len(got[i].Related(^Relation(0))) != len(want[i].Related(^Relation(0))) {
+				t.Errorf("%s: result %d differs:\n%s\n%s", crit.Matching, i, got[i].DumpStr(), want[i].DumpStr())
+			}
+		}
+	}
+}
+
+func TestSnapshotRejected(t *testing.T) {
+	needWordnet(t)
+	var buf bytes.Buffer
+	if err := wnInstance.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("%s", err)
+	}
+	corrupt := append([]byte{}, buf.Bytes()...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := LoadSnapshot(bytes.NewReader(corrupt)); err == nil {
+		t.Errorf("expected a corrupt snapshot to be rejected")
+	}
+	future := append([]byte{}, buf.Bytes()...)
+	future[len(snapshotMagic)] = snapshotVersion + 1
+	if _, err := LoadSnapshot(bytes.NewReader(future)); err == nil {
+		t.Errorf("expected a snapshot of another version to be rejected")
+	}
+	if _, err := LoadSnapshot(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Errorf("expected a truncated snapshot to be rejected")
+	}
+}
+
+func TestSourceChecksum(t *testing.T) {
+	needWordnet(t)
+	sum, err := SourceChecksum(os.DirFS(sourceCodeRelPath(PathToWordnetDataFiles)), ".", Options{POS: wnPOS})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if sum != wnInstance.SourceChecksum() {
+		t.Errorf("expected checksum %s, got %s", wnInstance.SourceChecksum(), sum)
+	}
+}
+
+func TestStaleSnapshot(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, f := range countsFS {
+		fsys[name] = &fstest.MapFile{Data: f.Data}
+	}
+	h, err := NewFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	filename := filepath.Join(t.TempDir(), "wordnet.snapshot")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := h.WriteSnapshot(f); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("%s", err)
+	}
+	snapshot, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	sum, err := SourceChecksum(fsys, ".", Options{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if _, err := LoadSnapshotChecked(bytes.NewReader(snapshot), sum); err != nil {
+		t.Errorf("expected a fresh snapshot to load: %s", err)
+	}
+	mapped, err := NewMappedChecked(filename, sum)
+	if err != nil {
+		t.Errorf("expected a fresh snapshot to map: %s", err)
+	} else {
+		mapped.Close()
+	}
+
+	// a changed count makes the snapshot stale
+	fsys["cntlist.rev"].Data = bytes.Replace(fsys["cntlist.rev"].Data, []byte(" 61"), []byte(" 62"), 1)
+	changed, err := SourceChecksum(fsys, ".", Options{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if changed == sum {
+		t.Fatalf("expected the checksum to change with a source file")
+	}
+	if _, err := LoadSnapshotChecked(bytes.NewReader(snapshot), changed); err == nil {
+		t.Errorf("expected a stale snapshot to be rejected")
+	}
+	if _, err := NewMappedChecked(filename, changed); err == nil {
+		t.Errorf("expected a stale snapshot to be rejected when mapped")
+	}
+	if _, err := LoadSnapshot(bytes.NewReader(snapshot)); err != nil {
+		t.Errorf("expected a snapshot to load without a checksum: %s", err)
+	}
+}
+
+// The checksum of a database loaded with some parts of speech covers
+// only their files
+func TestSourceChecksumPOS(t *testing.T) {
+	opts := Options{POS: PartOfSpeechList{Verb}}
+	h, err := NewFSWithOptions(morphyFS, ".", opts)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	sum, err := SourceChecksum(morphyFS, ".", opts)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if sum != h.SourceChecksum() {
+		t.Errorf("expected checksum %s, got %s", h.SourceChecksum(), sum)
+	}
+	all, err := SourceChecksum(morphyFS, ".", Options{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if all == sum {
+		t.Errorf("expected the checksum of all parts of speech to differ")
+	}
+}
@@ -0,0 +1,68 @@
+package wnram
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// posLetter returns the letter used for a part of speech in the data
+// files, distinguishing adjective satellites.
+func (c *clusterRec) posLetter() byte {
+	switch c.pos {
+	case Noun:
+		return 'n'
+	case Verb:
+		return 'v'
+	case Adjective:
+		if c.satellite {
+			return 's'
+		}
+		return 'a'
+	case Adverb:
+		return 'r'
+	}
+	return '?'
+}
+
+// A stable identifier for this meaning, composed of the synset's byte
+// offset and part of speech, e.g. "02084071-n".  Identifiers are
+// specific to a release of WordNet and may be resolved with
+// Handle.Synset.
+func (w *Lookup) ID() string {
+	return fmt.Sprintf("%08d-%c", w.c().offset, w.c().posLetter())
+}
+
+// parseSynsetID splits an identifier as returned by Lookup.ID.  Both
+// "a" and "s" are accepted for adjectives.
+func parseSynsetID(id string) (offsetKey, error) {
+	if len(id) != 10 || id[8] != '-' {
+		return offsetKey{}, fmt.Errorf("malformed synset id: %q", id)
+	}
+	for _, r := range id[:8] {
+		if !unicode.IsDigit(r) {
+			return offsetKey{}, fmt.Errorf("invalid chars in synset id: %q", id)
+		}
+	}
+	l := lexable(id[9:])
+	pos, err := l.lexPOS()
+	if err != nil {
+		return offsetKey{}, fmt.Errorf("synset id %q: %s", id, err)
+	}
+	return offsetKey{id[:8], pos}, nil
+}
+
+// Find the meaning identified by id, as returned by Lookup.ID.  The
+// result's word is the canonical synonym.
+func (h *Handle) Synset(id string) (Lookup, error) {
+	key, err := parseSynsetID(id)
+	if err != nil {
+		return Lookup{}, err
+	}
+	offset, _ := strconv.ParseUint(key.offset, 10, 32)
+	c, ok := h.findCluster(key.pos, uint32(offset))
+	if !ok {
+		return Lookup{}, fmt.Errorf("unknown synset: %s", id)
+	}
+	return h.lookup(c), nil
+}
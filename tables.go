@@ -0,0 +1,371 @@
+package wnram
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The database is stored in flat tables of fixed size records, which
+// refer to each other by position and to strings by their position in
+// a single string.  The tables contain no pointers, so they cost the
+// garbage collector nothing to scan, and they may be used in place
+// from a memory mapped snapshot (see NewMapped).
+
+// A string within Handle.text
+type str struct {
+	off, n uint32
+}
+
+// A synonym set.  Its words, semantic relations and verb frames are
+// consecutive entries of the words, edges and frames tables.
+type clusterRec struct {
+	offset    uint32
+	gloss     str
+	ili       str
+	words     uint32
+	edges     uint32
+	frames    uint32
+	nWords    uint16
+	nEdges    uint16
+	nFrames   uint8
+	pos       PartOfSpeech
+	lexFile   uint8
+	satellite bool
+}
+
+// A word of a synonym set.  Its lexical relations, verb frames and
+// example sentences are consecutive entries of the edges, frames and
+// sentences tables.
+type wordRec struct {
+	word        str
+	cluster     uint32
+	edges       uint32
+	frames      uint32
+	sentences   uint32
+	count       uint32
+	senseNumber uint16
+	nEdges      uint16
+	nFrames     uint8
+	nSentences  uint8
+	sense       uint8
+	marker      SyntacticMarker
+}
+
+// A relation to a cluster, and for lexical relations to the word at
+// position word within it
+type edgeRec struct {
+	rel     Relation
+	cluster uint32
+	word    uint32
+}
+
+// An entry of a table sorted by key, which lists entries first to
+// first+n of another table, e.g. the clusters of a word in the index
+type keyRec struct {
+	key      str
+	first, n uint32
+}
+
+// An inflected form from the exception lists and its base forms,
+// sorted by part of speech and then by form
+type excRec struct {
+	pos uint32
+	keyRec
+}
+
+// The number of tagged senses of a lemma, sorted by lemma and then by
+// part of speech
+type tagCountRec struct {
+	lemma str
+	pos   uint32
+	count uint32
+}
+
+// A sense key from index.sense which cannot be computed from the data
+// files, sorted by key
+type senseKeyRec struct {
+	key  str
+	word uint32
+}
+
+// A language loaded from Open Multilingual Wordnet files, with its
+// index in langKeys and its lemmas in translations
+type langRec struct {
+	code                 str
+	keys, nKeys          uint32
+	translations, nTrans uint32
+}
+
+// The lemmas, definitions and examples of a cluster in a language,
+// sorted by cluster, as entries of lists
+type translationRec struct {
+	cluster                   uint32
+	lemmas, nLemmas           uint32
+	definitions, nDefinitions uint32
+	examples, nExamples       uint32
+}
+
+func (h *Handle) str(s str) string {
+	return h.text[s.off : s.off+s.n]
+}
+
+func (h *Handle) strs(first, n uint32) []string {
+	if n == 0 {
+		return nil
+	}
+	ss := make([]string, n)
+	for i, s := range h.lists[first : first+n] {
+		ss[i] = h.str(s)
+	}
+	return ss
+}
+
+// search finds key in a table of keyRecs
+func (h *Handle) search(table []keyRec, key string) (keyRec, bool) {
+	i := sort.Search(len(table), func(i int) bool {
+		return h.str(table[i].key) >= key
+	})
+	if i < len(table) && h.str(table[i].key) == key {
+		return table[i], true
+	}
+	return keyRec{}, false
+}
+
+// lookupKeys returns the clusters listed for a key of an index
+func (h *Handle) lookupKeys(table []keyRec, key string) []uint32 {
+	if r, ok := h.search(table, key); ok {
+		return h.targets[r.first : r.first+r.n]
+	}
+	return nil
+}
+
+// lookupIndex returns the clusters of a normalized word, in sense order
+func (h *Handle) lookupIndex(key string) []uint32 {
+	return h.lookupKeys(h.index, key)
+}
+
+// findWord returns the word of a cluster which normalizes to key, or
+// else its canonical synonym
+func (h *Handle) findWord(c uint32, key string) uint32 {
+	r := &h.clusters[c]
+	for i, w := range h.words(r) {
+		if normalize(h.str(w.word)) == key {
+			return r.words + uint32(i)
+		}
+	}
+	return r.words
+}
+
+func (h *Handle) words(c *clusterRec) []wordRec {
+	return h.wordRecs[c.words : c.words+uint32(c.nWords)]
+}
+
+func (h *Handle) edges(c *clusterRec) []edgeRec {
+	return h.edgeRecs[c.edges : c.edges+uint32(c.nEdges)]
+}
+
+func (h *Handle) wordEdges(w *wordRec) []edgeRec {
+	return h.edgeRecs[w.edges : w.edges+uint32(w.nEdges)]
+}
+
+// firstWord returns the canonical synonym of a cluster
+func (h *Handle) firstWord(c uint32) string {
+	return h.str(h.wordRecs[h.clusters[c].words].word)
+}
+
+// findCluster returns the position of the cluster with the given part
+// of speech and offset, clusters being sorted by both
+func (h *Handle) findCluster(pos PartOfSpeech, offset uint32) (uint32, bool) {
+	i := sort.Search(len(h.clusters), func(i int) bool {
+		c := &h.clusters[i]
+		return c.pos > pos || (c.pos == pos && c.offset >= offset)
+	})
+	if i < len(h.clusters) && h.clusters[i].pos == pos && h.clusters[i].offset == offset {
+		return uint32(i), true
+	}
+	return 0, false
+}
+
+// A tableBuilder flattens the clusters built by a loader into tables.
+// Strings are interned, so that each is stored once however many
+// words, keys and lists it appears in.
+type tableBuilder struct {
+	h         *Handle
+	text      strings.Builder
+	interned  map[string]str
+	positions map[*cluster]uint32
+}
+
+func (b *tableBuilder) str(s string) str {
+	if r, ok := b.interned[s]; ok {
+		return r
+	}
+	r := str{uint32(b.text.Len()), uint32(len(s))}
+	b.text.WriteString(s)
+	b.interned[s] = r
+	return r
+}
+
+func (b *tableBuilder) list(ss []string) (uint32, uint32) {
+	first := uint32(len(b.h.lists))
+	for _, s := range ss {
+		b.h.lists = append(b.h.lists, b.str(s))
+	}
+	return first, uint32(len(ss))
+}
+
+// keys adds a sorted index of clusters to the targets table
+func (b *tableBuilder) keys(index map[string][]*cluster) []keyRec {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	table := make([]keyRec, 0, len(keys))
+	for _, key := range keys {
+		r := keyRec{key: b.str(key), first: uint32(len(b.h.targets)), n: uint32(len(index[key]))}
+		for _, c := range index[key] {
+			b.h.targets = append(b.h.targets, b.positions[c])
+		}
+		table = append(table, r)
+	}
+	return table
+}
+
+// build flattens clusters, sorted by part of speech and offset, and
+// the tables which refer to them.
+func (b *tableBuilder) build(db []*cluster, index map[string][]*cluster, excs map[PartOfSpeech]exceptions, tagSenseCounts map[lemmaKey]int, senseKeys map[string]senseRef, verbSentences map[int]string, lexNames []string, languages map[string]*language) {
+	h := b.h
+	b.interned = make(map[string]str)
+	b.positions = make(map[*cluster]uint32, len(db))
+	for i, c := range db {
+		b.positions[c] = uint32(i)
+	}
+
+	h.clusters = make([]clusterRec, 0, len(db))
+	for i, c := range db {
+		offset, _ := strconv.ParseUint(c.offset, 10, 32)
+		r := clusterRec{
+			offset:    uint32(offset),
+			gloss:     b.str(c.gloss),
+			ili:       b.str(c.ili),
+			words:     uint32(len(h.wordRecs)),
+			edges:     uint32(len(h.edgeRecs)),
+			frames:    uint32(len(h.frames)),
+			nWords:    uint16(len(c.words)),
+			nEdges:    uint16(len(c.relations)),
+			nFrames:   uint8(len(c.frames)),
+			pos:       c.pos,
+			lexFile:   c.lexFile,
+			satellite: c.satellite,
+		}
+		h.frames = append(h.frames, c.frames...)
+		for _, rel := range c.relations {
+			h.edgeRecs = append(h.edgeRecs, edgeRec{rel: rel.rel, cluster: b.positions[rel.target]})
+		}
+		for _, w := range c.words {
+			h.wordRecs = append(h.wordRecs, wordRec{
+				word:        b.str(w.word),
+				cluster:     uint32(i),
+				edges:       uint32(len(h.edgeRecs)),
+				frames:      uint32(len(h.frames)),
+				sentences:   uint32(len(h.sentences)),
+				count:       w.count,
+				senseNumber: w.senseNumber,
+				nEdges:      uint16(len(w.relations)),
+				nFrames:     uint8(len(w.frames)),
+				nSentences:  uint8(len(w.sentences)),
+				sense:       w.sense,
+				marker:      w.marker,
+			})
+			for _, rel := range w.relations {
+				h.edgeRecs = append(h.edgeRecs, edgeRec{rel.rel, b.positions[rel.target], uint32(rel.wordNumber)})
+			}
+			h.frames = append(h.frames, w.frames...)
+			h.sentences = append(h.sentences, w.sentences...)
+		}
+		h.clusters = append(h.clusters, r)
+	}
+	h.index = b.keys(index)
+
+	for _, pos := range []PartOfSpeech{Noun, Verb, Adjective, Adverb} {
+		exc := excs[pos]
+		forms := make([]string, 0, len(exc))
+		for form := range exc {
+			forms = append(forms, form)
+		}
+		sort.Strings(forms)
+		for _, form := range forms {
+			r := excRec{pos: uint32(pos), keyRec: keyRec{key: b.str(form)}}
+			r.first, r.n = b.list(exc[form])
+			h.exceptions = append(h.exceptions, r)
+		}
+	}
+
+	lemmas := make([]lemmaKey, 0, len(tagSenseCounts))
+	for k := range tagSenseCounts {
+		lemmas = append(lemmas, k)
+	}
+	sort.Slice(lemmas, func(i, j int) bool {
+		if lemmas[i].lemma != lemmas[j].lemma {
+			return lemmas[i].lemma < lemmas[j].lemma
+		}
+		return lemmas[i].pos < lemmas[j].pos
+	})
+	for _, k := range lemmas {
+		h.tagCounts = append(h.tagCounts, tagCountRec{b.str(k.lemma), uint32(k.pos), uint32(tagSenseCounts[k])})
+	}
+
+	keys := make([]string, 0, len(senseKeys))
+	for key := range senseKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ref := senseKeys[key]
+		h.senseKeys = append(h.senseKeys, senseKeyRec{b.str(key), h.clusters[b.positions[ref.cluster]].words + uint32(ref.word)})
+	}
+
+	for n, s := range verbSentences {
+		for len(h.verbSentences) <= n {
+			h.verbSentences = append(h.verbSentences, str{})
+		}
+		h.verbSentences[n] = b.str(s)
+	}
+	for _, name := range lexNames {
+		h.lexNames = append(h.lexNames, b.str(name))
+	}
+
+	codes := make([]string, 0, len(languages))
+	for code := range languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		lang := languages[code]
+		keys := b.keys(lang.index)
+		r := langRec{
+			code:         b.str(code),
+			keys:         uint32(len(h.langKeys)),
+			nKeys:        uint32(len(keys)),
+			translations: uint32(len(h.translations)),
+		}
+		h.langKeys = append(h.langKeys, keys...)
+		for _, c := range db {
+			lemmas, defs, examples := lang.lemmas[c], lang.definitions[c], lang.examples[c]
+			if len(lemmas)+len(defs)+len(examples) == 0 {
+				continue
+			}
+			t := translationRec{cluster: b.positions[c]}
+			t.lemmas, t.nLemmas = b.list(lemmas)
+			t.definitions, t.nDefinitions = b.list(defs)
+			t.examples, t.nExamples = b.list(examples)
+			h.translations = append(h.translations, t)
+		}
+		r.nTrans = uint32(len(h.translations)) - r.translations
+		h.languages = append(h.languages, r)
+	}
+	h.text = b.text.String()
+	b.interned = nil
+}
@@ -0,0 +1,253 @@
+package wnram
+
+import "sort"
+
+// The taxonomy is the graph of hypernyms, in which a synset may have
+// several hypernyms.  Instances such as "Einstein" have instance
+// hypernyms rather than hypernyms, and both are followed, as in NLTK.
+const (
+	hypernymRelations = Hypernym | InstanceHypernym
+	hyponymRelations  = Hyponym | InstanceHyponym
+)
+
+// hasEdge reports whether a cluster has a semantic relation of one of
+// the types in r
+func (h *Handle) hasEdge(c uint32, r Relation) bool {
+	for _, e := range h.edges(&h.clusters[c]) {
+		if e.rel&r != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether this synset has no hypernyms, e.g. "entity" for nouns.  Verbs
+// have many roots.
+func (w *Lookup) IsRoot() bool {
+	return !w.h.hasEdge(w.cluster, hypernymRelations)
+}
+
+// Whether this synset has no hyponyms, i.e. nothing is a kind or an
+// instance of it.
+func (w *Lookup) IsLeaf() bool {
+	return !w.h.hasEdge(w.cluster, hyponymRelations)
+}
+
+// The synsets of the given parts of speech (or all) which have no
+// hypernyms, see Lookup.IsRoot.
+func (h *Handle) Roots(pos PartOfSpeechList) (roots []Lookup) {
+	h.Iterate(pos, func(l Lookup) error {
+		if l.IsRoot() {
+			roots = append(roots, l)
+		}
+		return nil
+	})
+	return roots
+}
+
+// The synsets of the given parts of speech (or all) which have no
+// hyponyms, see Lookup.IsLeaf.
+func (h *Handle) Leaves(pos PartOfSpeechList) (leaves []Lookup) {
+	h.Iterate(pos, func(l Lookup) error {
+		if l.IsLeaf() {
+			leaves = append(leaves, l)
+		}
+		return nil
+	})
+	return leaves
+}
+
+// Every path from this synset up to a root, following hypernyms and
+// instance hypernyms.  Each path starts with this synset and ends with
+// a root, e.g. dog, canine, carnivore, ..., entity.  Adjectives and
+// adverbs have no hypernyms, so their only path is the synset itself.
+// See HypernymPathsVia.
+func (w *Lookup) HypernymPaths() [][]Lookup {
+	return w.HypernymPathsVia(hypernymRelations)
+}
+
+// HypernymPaths following only the relations in r, which may be
+// Hypernym, InstanceHypernym or both.  With Hypernym alone, instances
+// such as "Einstein" are roots.
+func (w *Lookup) HypernymPathsVia(r Relation) (paths [][]Lookup) {
+	r &= hypernymRelations
+	var path []uint32
+	var walk func(c uint32)
+	walk = func(c uint32) {
+		for _, p := range path {
+			if p == c {
+				return // a cycle, which leads to no root
+			}
+		}
+		path = append(path, c)
+		root := true
+		for _, e := range w.h.edges(&w.h.clusters[c]) {
+			if e.rel&r != 0 {
+				root = false
+				walk(e.cluster)
+			}
+		}
+		if root {
+			found := make([]Lookup, len(path))
+			found[0] = *w
+			for i := 1; i < len(path); i++ {
+				found[i] = w.h.lookup(path[i])
+			}
+			paths = append(paths, found)
+		}
+		path = path[:len(path)-1]
+	}
+	walk(w.cluster)
+	return paths
+}
+
+// The number of hypernyms between this synset and the nearest root, zero
+// for roots.  The same as MinDepth.
+func (w *Lookup) Depth() int {
+	return w.MinDepth()
+}
+
+// The length of the shortest path to a root, see HypernymPaths.
+func (w *Lookup) MinDepth() int {
+	return w.MinDepthVia(hypernymRelations)
+}
+
+// MinDepth following only the relations in r, see HypernymPathsVia.
+func (w *Lookup) MinDepthVia(r Relation) int {
+	r &= hypernymRelations
+	seen := map[uint32]bool{w.cluster: true}
+	level := []uint32{w.cluster}
+	for depth := 0; len(level) > 0; depth++ {
+		var next []uint32
+		for _, c := range level {
+			root := true
+			for _, e := range w.h.edges(&w.h.clusters[c]) {
+				if e.rel&r == 0 {
+					continue
+				}
+				root = false
+				if !seen[e.cluster] {
+					seen[e.cluster] = true
+					next = append(next, e.cluster)
+				}
+			}
+			if root {
+				return depth
+			}
+		}
+		level = next
+	}
+	return 0
+}
+
+// The length of the longest path to a root, see HypernymPaths.
+func (w *Lookup) MaxDepth() int {
+	return w.MaxDepthVia(hypernymRelations)
+}
+
+// MaxDepth following only the relations in r, see HypernymPathsVia.
+func (w *Lookup) MaxDepthVia(r Relation) int {
+	return w.h.maxDepth(w.cluster, r&hypernymRelations, map[uint32]int{})
+}
+
+// maxDepth returns the length of the longest path from a cluster to a
+// root following relations r, memoizing the depths of the clusters
+// visited.  Clusters being visited are marked -1, so that cycles are not
+// followed.
+func (h *Handle) maxDepth(c uint32, r Relation, depths map[uint32]int) int {
+	if d, ok := depths[c]; ok {
+		return d
+	}
+	depths[c] = -1
+	max := 0
+	for _, e := range h.edges(&h.clusters[c]) {
+		if e.rel&r == 0 {
+			continue
+		}
+		if d := h.maxDepth(e.cluster, r, depths); d >= 0 && d+1 > max {
+			max = d + 1
+		}
+	}
+	depths[c] = max
+	return max
+}
+
+// A hypernym shared by several synsets, see Handle.LowestCommonHypernyms
+type CommonHypernym struct {
+	Lookup
+	// The length of the longest path from the hypernym to a root
+	Depth int
+}
+
+// ancestors returns a cluster and all the clusters reached from it by
+// following relations r
+func (h *Handle) ancestors(c uint32, r Relation) map[uint32]bool {
+	seen := map[uint32]bool{c: true}
+	todo := []uint32{c}
+	for len(todo) > 0 {
+		c, todo = todo[len(todo)-1], todo[:len(todo)-1]
+		for _, e := range h.edges(&h.clusters[c]) {
+			if e.rel&r != 0 && !seen[e.cluster] {
+				seen[e.cluster] = true
+				todo = append(todo, e.cluster)
+			}
+		}
+	}
+	return seen
+}
+
+// The most specific hypernyms which the synsets have in common, i.e.
+// those of their common hypernyms which are not hypernyms of another.
+// A synset counts as its own hypernym, so the lowest common hypernym of
+// stroll and walk is walk.  Results are ordered from the deepest.
+// Follows both hypernyms and instance hypernyms, see
+// LowestCommonHypernymsVia.
+func (h *Handle) LowestCommonHypernyms(lookups ...Lookup) []CommonHypernym {
+	return h.LowestCommonHypernymsVia(hypernymRelations, lookups...)
+}
+
+// LowestCommonHypernyms following only the relations in r, which may
+// be Hypernym, InstanceHypernym or both.  With Hypernym alone, instances
+// such as "Einstein" have no hypernyms.
+func (h *Handle) LowestCommonHypernymsVia(r Relation, lookups ...Lookup) []CommonHypernym {
+	r &= hypernymRelations
+	if len(lookups) == 0 {
+		return nil
+	}
+	common := h.ancestors(lookups[0].cluster, r)
+	for _, l := range lookups[1:] {
+		other := h.ancestors(l.cluster, r)
+		for c := range common {
+			if !other[c] {
+				delete(common, c)
+			}
+		}
+	}
+	// drop those which are hypernyms of another common hypernym, which
+	// are common hypernyms too, unless they form a cycle
+	above := map[uint32]map[uint32]bool{}
+	lowest := map[uint32]bool{}
+	for c := range common {
+		above[c] = h.ancestors(c, r)
+		lowest[c] = true
+	}
+	for c := range common {
+		for a := range above[c] {
+			if a != c && !above[a][c] {
+				delete(lowest, a)
+			}
+		}
+	}
+	depths := map[uint32]int{}
+	found := make([]CommonHypernym, 0, len(lowest))
+	for c := range lowest {
+		found = append(found, CommonHypernym{Lookup: h.lookup(c), Depth: h.maxDepth(c, r, depths)})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Depth != found[j].Depth {
+			return found[i].Depth > found[j].Depth
+		}
+		return found[i].cluster < found[j].cluster
+	})
+	return found
+}
@@ -0,0 +1,122 @@
+package wnram
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHypernymPaths(t *testing.T) {
+	needWordnet(t)
+	stroll, err := wnInstance.Synset("01921973-v")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	paths := stroll.HypernymPaths()
+	if len(paths) != 1 {
+		t.Fatalf("expected one path, got %d", len(paths))
+	}
+	var words []string
+	for _, l := range paths[0] {
+		words = append(words, l.Word())
+	}
+	if got := strings.Join(words, ", "); got != "stroll, walk, travel" {
+		t.Errorf("unexpected path for stroll: %s", got)
+	}
+	if !paths[0][2].IsRoot() || stroll.IsRoot() || !stroll.IsLeaf() {
+		t.Errorf("expected travel to be a root and stroll a leaf")
+	}
+
+	// limber is a kind of warm up, which is a kind of both exercise and
+	// work
+	limber, _ := wnInstance.Synset("00100891-v")
+	if n := len(limber.HypernymPaths()); n != 2 {
+		t.Errorf("expected two paths for limber, got %d", n)
+	}
+	if limber.Depth() != 3 || limber.MinDepth() != 3 || limber.MaxDepth() != 4 {
+		t.Errorf("unexpected depths for limber: %d, %d", limber.MinDepth(), limber.MaxDepth())
+	}
+}
+
+func TestRoots(t *testing.T) {
+	needWordnet(t)
+	roots := wnInstance.Roots(PartOfSpeechList{Verb})
+	if len(roots) == 0 {
+		t.Fatalf("expected verb roots")
+	}
+	for _, r := range roots {
+		if r.POS() != Verb || r.Depth() != 0 || len(r.HypernymPaths()) != 1 {
+			t.Errorf("unexpected root %s", r.String())
+		}
+	}
+	wnInstance.Iterate(PartOfSpeechList{Verb}, func(l Lookup) error {
+		for _, p := range l.HypernymPaths() {
+			if d := len(p) - 1; d < l.MinDepth() || d > l.MaxDepth() || !p[d].IsRoot() {
+				t.Fatalf("unexpected path for %s: length %d", l.ID(), d)
+			}
+		}
+		return nil
+	})
+	for _, l := range wnInstance.Leaves(PartOfSpeechList{Verb}) {
+		if len(l.Related(Hyponym)) != 0 {
+			t.Fatalf("unexpected leaf %s", l.String())
+		}
+	}
+}
+
+func TestLowestCommonHypernyms(t *testing.T) {
+	needWordnet(t)
+	stroll, _ := wnInstance.Synset("01921973-v")
+	sprint, _ := wnInstance.Synset("01932532-v")
+	walk := stroll.Related(Hypernym)[0]
+
+	common := wnInstance.LowestCommonHypernyms(stroll, sprint, walk)
+	if len(common) != 1 || common[0].Word() != "travel" || common[0].Depth != 0 {
+		t.Errorf("expected travel to be the lowest common hypernym, got %v", common)
+	}
+	common = wnInstance.LowestCommonHypernyms(stroll, walk)
+	if len(common) != 1 || common[0].ID() != walk.ID() || common[0].Depth != 1 {
+		t.Errorf("expected walk to be the lowest common hypernym, got %v", common)
+	}
+	if common := wnInstance.LowestCommonHypernymsVia(InstanceHypernym, stroll, sprint); len(common) != 0 {
+		t.Errorf("expected no common instance hypernyms, got %v", common)
+	}
+
+	// the common hypernyms of limber and work are work and use, of which
+	// work is the lowest
+	limber, _ := wnInstance.Synset("00100891-v")
+	work, _ := wnInstance.Synset("02413117-v")
+	common = wnInstance.LowestCommonHypernyms(limber, work)
+	if len(common) != 1 || common[0].ID() != work.ID() {
+		t.Errorf("expected work to be the lowest common hypernym, got %v", common)
+	}
+}
+
+// Instance hypernyms may be left out of paths and depths
+func TestHypernymPathsVia(t *testing.T) {
+	fsys := fstest.MapFS{"data.noun": {Data: append(append([]byte{}, taxonomyFS["data.noun"].Data...),
+		"00000080 18 n 01 Lassie 0 001 @i 00000060 n 0000 | a fictional collie\n"...)}}
+	h, err := NewFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	lassie, err := h.Synset("00000080-n")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if paths := lassie.HypernymPaths(); len(paths) != 1 || len(paths[0]) != 6 || paths[0][5].Word() != "entity" {
+		t.Errorf("expected a path from Lassie to entity, got %v", paths)
+	}
+	if lassie.MinDepth() != 5 || lassie.MaxDepth() != 5 {
+		t.Errorf("unexpected depths for Lassie: %d, %d", lassie.MinDepth(), lassie.MaxDepth())
+	}
+	if paths := lassie.HypernymPathsVia(Hypernym); len(paths) != 1 || len(paths[0]) != 1 {
+		t.Errorf("expected Lassie to be a root without instance hypernyms, got %v", paths)
+	}
+	if lassie.MinDepthVia(Hypernym) != 0 || lassie.MaxDepthVia(Hypernym) != 0 {
+		t.Errorf("unexpected depths for Lassie without instance hypernyms: %d, %d", lassie.MinDepthVia(Hypernym), lassie.MaxDepthVia(Hypernym))
+	}
+	if lassie.MinDepthVia(InstanceHypernym) != 1 || lassie.MaxDepthVia(InstanceHypernym) != 1 {
+		t.Errorf("unexpected depths for Lassie by instance hypernyms: %d, %d", lassie.MinDepthVia(InstanceHypernym), lassie.MaxDepthVia(InstanceHypernym))
+	}
+}
@@ -2,18 +2,39 @@ package wnram
 
 import (
 	"fmt"
-	"os"
-	"path"
-	"path/filepath"
+	"io/fs"
 	"strings"
-	"time"
+	"sync"
 )
 
 // An initialized read-only, in-ram instance of the wordnet database.
 // May safely be shared by multiple threads of execution
 type Handle struct {
-	index map[string][]*cluster
-	db    []*cluster
+	clusters      []clusterRec
+	wordRecs      []wordRec
+	edgeRecs      []edgeRec
+	frames        []uint8
+	sentences     []uint16
+	targets       []uint32 // clusters listed by index entries
+	lists         []str    // strings listed by other tables
+	index         []keyRec
+	exceptions    []excRec
+	tagCounts     []tagCountRec
+	senseKeys     []senseKeyRec
+	verbSentences []str
+	lexNames      []str
+	languages     []langRec
+	langKeys      []keyRec
+	translations  []translationRec
+	text          string
+	checksum      string
+	mapping       []byte // the memory mapped snapshot, if any
+
+	depthsOnce sync.Once
+	depths     [AdjectiveSatellite]int // the greatest depth by part of speech
+	inOnce     sync.Once
+	in         []inEdge // the edges into each cluster, see inEdges
+	inStart    []uint32
 }
 
 type index struct {
@@ -24,33 +45,24 @@ type index struct {
 
 // The results of a search against the wordnet database
 type Lookup struct {
-	word    string   // the word the user searched for
-	cluster *cluster // the discoverd synonym set
+	h       *Handle // the database searched
+	cluster uint32  // the discoverd synonym set
+	word    uint32  // the word found, see foreignWord
+	query   string  // the word searched for, if any
 }
 
-type syntacticRelation struct {
-	rel        Relation
-	target     *cluster
-	wordNumber uint8
-}
-
-type semanticRelation struct {
-	rel    Relation
-	target *cluster
-}
+// Marks a Lookup's word as a lemma in another language, at that
+// position of Handle.lists, rather than a word of the cluster.
+const foreignWord = 1 << 31
 
-type word struct {
-	sense     uint8
-	word      string
-	relations []syntacticRelation
+// lookup returns a Lookup of the canonical synonym of a cluster
+func (h *Handle) lookup(c uint32) Lookup {
+	return Lookup{h: h, cluster: c, word: h.clusters[c].words}
 }
 
-type cluster struct {
-	pos       PartOfSpeech
-	words     []word
-	gloss     string
-	relations []semanticRelation
-	debug     string
+// lookupWord returns a Lookup of a word of a cluster
+func (h *Handle) lookupWord(w uint32) Lookup {
+	return Lookup{h: h, cluster: h.wordRecs[w].cluster, word: w}
 }
 
 // Parts of speech
@@ -72,14 +84,41 @@ func (l PartOfSpeechList) Contains(want PartOfSpeech) bool {
 	return false
 }
 
+// matches reports whether the cluster has one of the listed parts of
+// speech.  Adjective matches satellites too, AdjectiveSatellite matches
+// only satellites.
+func (l PartOfSpeechList) matches(c *clusterRec) bool {
+	for _, p := range l {
+		if p == c.pos || (p == AdjectiveSatellite && c.satellite) {
+			return true
+		}
+	}
+	return false
+}
+
 const (
 	Noun PartOfSpeech = iota
 	Verb
 	Adjective
-	//	AdjectiveSatellite
 	Adverb
+	// Adjectives which are not the head of an adjective cluster.  Lookup
+	// results report their part of speech as Adjective (see
+	// Lookup.IsSatellite), this value selects only satellites in
+	// Criteria and Iterate.
+	AdjectiveSatellite
 )
 
+// posFromName maps the part of speech names used in file names
+// (noun, verb, adj, adv) to a PartOfSpeech
+func posFromName(name string) (PartOfSpeech, bool) {
+	for _, pos := range []PartOfSpeech{Noun, Verb, Adjective, Adverb} {
+		if pos.String() == name {
+			return pos, true
+		}
+	}
+	return 0, false
+}
+
 func (pos PartOfSpeech) String() string {
 	switch pos {
 	case Noun:
@@ -90,6 +129,8 @@ func (pos PartOfSpeech) String() string {
 		return "adj"
 	case Adverb:
 		return "adv"
+	case AdjectiveSatellite:
+		return "adj satellite"
 	}
 	return "unknown"
 }
@@ -138,34 +179,44 @@ const (
 const Pertainym = DerivedFromAdjective
 
 func (w *Lookup) String() string {
-	return fmt.Sprintf("%q (%s)", w.word, w.cluster.pos.String())
+	return fmt.Sprintf("%q (%s)", w.Word(), w.c().pos.String())
 }
 
-// The specific word that was found
+// c returns the record of the cluster that was found
+func (w *Lookup) c() *clusterRec {
+	return &w.h.clusters[w.cluster]
+}
+
+// The specific word that was found.  For results of Handle.Lookup this
+// is the string searched for, or with Criteria.Morphology the base form
+// of it which was found; otherwise it is the word as written in the
+// database.
 func (w *Lookup) Word() string {
-	return w.word
+	if w.query != "" {
+		return w.query
+	}
+	if w.word&foreignWord != 0 {
+		return w.h.str(w.h.lists[w.word&^foreignWord])
+	}
+	return w.h.str(w.h.wordRecs[w.word].word)
 }
 
 // A canonical synonym for this word
 func (w *Lookup) Lemma() string {
-	return w.cluster.words[0].word
+	return w.h.firstWord(w.cluster)
 }
 
 // A description of this meaning
 func (w *Lookup) Gloss() string {
-	return w.cluster.gloss
+	return w.h.str(w.c().gloss)
 }
 
 func (w *Lookup) DumpStr() string {
 	s := fmt.Sprintf("Word: %s\n", w.String())
 	s += fmt.Sprintf("Synonyms: ")
-	words := []string{}
-	for _, w := range w.cluster.words {
-		words = append(words, w.word)
-	}
-	s += strings.Join(words, ", ") + "\n"
-	s += fmt.Sprintf("%d semantic relationships\n", len(w.cluster.relations))
-	s += "| " + w.cluster.gloss + "\n"
+	s += strings.Join(w.Synonyms(), ", ") + "\n"
+	s += fmt.Sprintf("%d semantic relationships\n", w.c().nEdges)
+	s += "| " + w.Gloss() + "\n"
 	return s
 }
 
@@ -174,39 +225,56 @@ func (w *Lookup) Dump() {
 }
 
 func (w *Lookup) POS() PartOfSpeech {
-	return w.cluster.pos
+	return w.c().pos
+}
+
+// Whether this is an adjective satellite, i.e. an adjective which is
+// similar to the head adjective of its cluster rather than a head
+// itself.
+func (w *Lookup) IsSatellite() bool {
+	return w.c().satellite
+}
+
+// The head adjective of an adjective satellite's cluster, e.g. "tasty"
+// for "yummy".  Returns false for anything but adjective satellites.
+func (w *Lookup) Head() (Lookup, bool) {
+	c, ok := w.h.head(w.cluster)
+	if !ok {
+		return Lookup{}, false
+	}
+	return w.h.lookup(c), true
 }
 
 func (w *Lookup) Synonyms() (synonyms []string) {
-	for _, w := range w.cluster.words {
-		synonyms = append(synonyms, w.word)
+	for _, word := range w.h.words(w.c()) {
+		synonyms = append(synonyms, w.h.str(word.word))
 	}
 	return synonyms
 }
 
+// wordRec returns the record of the word that was found within the
+// cluster, or nil if it was found in another language
+func (w *Lookup) wordRec() *wordRec {
+	if w.word&foreignWord != 0 {
+		return nil
+	}
+	return &w.h.wordRecs[w.word]
+}
+
 // Get words related to this word.  r is a bitfield of relation types
 // to include
 func (w *Lookup) Related(r Relation) (relationships []Lookup) {
 	// first look for semantic relationships
-	for _, rel := range w.cluster.relations {
+	for _, rel := range w.h.edges(w.c()) {
 		if rel.rel&r != Relation(0) {
-			relationships = append(relationships, Lookup{
-				word:    rel.target.words[0].word,
-				cluster: rel.target,
-			})
+			relationships = append(relationships, w.h.lookup(rel.cluster))
 		}
 	}
 	// next let's look for syntactic relationships
-	key := normalize(w.word)
-	for _, word := range w.cluster.words {
-		if key == word.word {
-			for _, rel := range word.relations {
-				if rel.rel&r != Relation(0) {
-					relationships = append(relationships, Lookup{
-						word:    rel.target.words[rel.wordNumber].word,
-						cluster: rel.target,
-					})
-				}
+	if word := w.wordRec(); word != nil {
+		for _, rel := range w.h.wordEdges(word) {
+			if rel.rel&r != Relation(0) {
+				relationships = append(relationships, w.h.lookupWord(w.h.clusters[rel.cluster].words+rel.word))
 			}
 		}
 	}
@@ -215,110 +283,50 @@ func (w *Lookup) Related(r Relation) (relationships []Lookup) {
 }
 
 // Initialize a new in-ram WordNet databases reading files from the
-// specified directory.
+// specified directory.  Files may be compressed with gzip (e.g.
+// data.noun.gz), or bundled in a zip or tar.gz archive.
 func New(dir string) (*Handle, error) {
-	cnt := 0
-	type ix struct {
-		index string
-		pos   PartOfSpeech
-	}
-	byOffset := map[ix]*cluster{}
-	err := filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
-		start := time.Now()
-		if err != nil || info.IsDir() {
-			return err
-		}
-
-		// Skip '^.', '~$', and non-files.
-		if strings.HasPrefix(path.Base(filename), ".") || strings.HasSuffix(filename, "~") || strings.HasSuffix(filename, "#") {
-			return nil
-		}
-		// read only data files
-		if !strings.HasPrefix(path.Base(filename), "data") {
-			return nil
-		}
-
-		err = inPlaceReadLineFromPath(filename, func(data []byte, line, offset int64) error {
-			cnt++
-			if p, err := parseLine(data, line, offset); err != nil {
-				return fmt.Errorf("%s:%d: %s", err)
-			} else if p != nil {
-				// first, let's identify the cluster
-				index := ix{p.byteOffset, p.pos}
-				c, ok := byOffset[index]
-				if !ok {
-					c = &cluster{}
-					byOffset[index] = c
-				}
-				// now update
-				c.pos = p.pos
-				c.words = p.words
-				c.gloss = p.gloss
-				c.debug = p.byteOffset
-
-				// now let's build relations
-				for _, r := range p.rels {
-					rindex := ix{r.offset, r.pos}
-					rcluster, ok := byOffset[rindex]
-					if !ok {
-						// create the other side of the relationship
-						rcluster = &cluster{}
-						byOffset[rindex] = rcluster
-					}
-					if r.isSemantic {
-						c.relations = append(c.relations, semanticRelation{
-							rel:    r.rel,
-							target: rcluster,
-						})
-					} else {
-						if int(r.source) >= len(c.words) {
-							return fmt.Errorf("%s:%d: error parsing relations, bogus source (words: %d, offset: %d) [%s]", filename, line, r.source, len(c.words), string(data))
-						}
-						c.words[r.source].relations = append(c.words[r.source].relations, syntacticRelation{
-							rel:        r.rel,
-							target:     rcluster,
-							wordNumber: r.dest,
-						})
-					}
-				}
-
-			}
-			return nil
-		})
-		fmt.Printf("%s in %s\n", filename, time.Since(start).String())
-		return err
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// now that we've built up the in ram database, lets' index it
-	h := Handle{
-		db:    make([]*cluster, 0, len(byOffset)),
-		index: make(map[string][]*cluster),
-	}
-	for _, c := range byOffset {
-		if len(c.words) == 0 {
-			return nil, fmt.Errorf("ERROR, internal consistency error -> cluster without words %v\n", c)
-		}
-		// add to the global slice of synsets (supports iteration)
-		h.db = append(h.db, c)
-
-		// now index all the strings
-		for _, w := range c.words {
-			key := normalize(w.word)
-			v, _ := h.index[key]
-			v = append(v, c)
-			h.index[key] = v
-		}
-	}
+	return NewWithOptions(dir, Options{})
+}
 
-	return &h, nil
+// Initialize a new in-ram WordNet database reading files from the
+// specified directory of a file system, e.g. an embed.FS, a zip.Reader
+// or a fstest.MapFS.  The wnram/data package embeds the bundled
+// database, which must be loaded with NewFSWithOptions as it has no
+// nouns.
+func NewFS(fsys fs.FS, root string) (*Handle, error) {
+	return newFS(fsys, root, Options{})
 }
 
 type Criteria struct {
 	Matching string
 	POS      PartOfSpeechList
+	// Also find base forms of Matching, e.g. "geese" finds "goose".
+	// See Handle.Lemmatize.
+	Morphology bool
+	// Only find adjectives with this syntactic marker, unless NoMarker
+	Marker SyntacticMarker
+	// Only find meanings from this lexicographer file, e.g. "noun.animal"
+	LexFile string
+	// Search lemmas in this language, e.g. "fra", as loaded from Open
+	// Multilingual Wordnet tab files.  Empty or "eng" for English.
+	// Morphology applies only to English.
+	Language string
+}
+
+// matches reports whether a result satisfies the criteria, other than
+// the search string
+func (crit *Criteria) matches(l *Lookup) bool {
+	if len(crit.POS) > 0 && !crit.POS.matches(l.c()) {
+		return false
+	}
+	if crit.Marker != NoMarker && l.SyntacticMarker() != crit.Marker {
+		return false
+	}
+	if crit.LexFile != "" && l.LexFile() != crit.LexFile {
+		return false
+	}
+	return true
 }
 
 func normalize(in string) string {
@@ -331,38 +339,37 @@ func (h *Handle) Lookup(crit Criteria) ([]Lookup, error) {
 		return nil, fmt.Errorf("empty string passed as criteria to lookup")
 	}
 	searchStr := normalize(crit.Matching)
-	clusters, _ := h.index[searchStr]
 	found := []Lookup{}
-	for _, c := range clusters {
-		if len(crit.POS) > 0 {
-			satisfied := false
-			for _, p := range crit.POS {
-				if p == c.pos {
-					satisfied = true
-					break
-				}
-			}
-			if !satisfied {
-				continue
+	if crit.Language != "" && crit.Language != "eng" {
+		lang, ok := h.language(crit.Language)
+		if !ok {
+			return nil, fmt.Errorf("no lemmas loaded for language %q", crit.Language)
+		}
+		for _, c := range h.lookupKeys(h.langKeys[lang.keys:lang.keys+lang.nKeys], searchStr) {
+			l := Lookup{h: h, cluster: c, word: h.findTranslation(lang, c, searchStr), query: crit.Matching}
+			if crit.matches(&l) {
+				found = append(found, l)
 			}
 		}
-		found = append(found, Lookup{
-			word:    crit.Matching,
-			cluster: c,
-		})
+		return found, nil
+	} else if crit.Morphology {
+		return h.lookupBaseForms(crit), nil
+	}
+	for _, c := range h.lookupIndex(searchStr) {
+		l := Lookup{h: h, cluster: c, word: h.findWord(c, searchStr), query: crit.Matching}
+		if crit.matches(&l) {
+			found = append(found, l)
+		}
 	}
 	return found, nil
 }
 
 func (h *Handle) Iterate(pos PartOfSpeechList, cb func(Lookup) error) error {
-	for _, c := range h.db {
-		if !pos.Empty() && !pos.Contains(c.pos) {
+	for i := range h.clusters {
+		if !pos.Empty() && !pos.matches(&h.clusters[i]) {
 			continue
 		}
-		err := cb(Lookup{
-			word:    c.words[0].word,
-			cluster: c,
-		})
+		err := cb(h.lookup(uint32(i)))
 		if err != nil {
 			return err
 		}
@@ -1,9 +1,16 @@
 package wnram
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"testing"
+	"testing/fstest"
 )
 
 const PathToWordnetDataFiles = "./data"
@@ -16,8 +23,34 @@ func sourceCodeRelPath(suffix string) string {
 var wnInstance *Handle
 var wnErr error
 
+// The parts of speech with data files in the data directory.  data.noun
+// is not bundled, so the relations of other parts of speech to nouns are
+// dropped, and tests which need nouns are skipped unless it is added.
+var wnPOS PartOfSpeechList
+
 func init() {
-	wnInstance, wnErr = New(sourceCodeRelPath(PathToWordnetDataFiles))
+	dir := sourceCodeRelPath(PathToWordnetDataFiles)
+	for _, pos := range []PartOfSpeech{Noun, Verb, Adjective, Adverb} {
+		if _, err := os.Stat(path.Join(dir, "data."+pos.String())); err == nil {
+			wnPOS = append(wnPOS, pos)
+		}
+	}
+	wnInstance, wnErr = NewWithOptions(dir, Options{POS: wnPOS})
+}
+
+// needWordnet skips a test unless the database was loaded with data
+// files for all of the given parts of speech, and fails it if the
+// database could not be loaded.
+func needWordnet(t *testing.T, pos ...PartOfSpeech) {
+	t.Helper()
+	for _, p := range pos {
+		if !wnPOS.Contains(p) {
+			t.Skipf("no data.%s in %s", p, PathToWordnetDataFiles)
+		}
+	}
+	if wnErr != nil {
+		t.Fatalf("Can't initialize: %s", wnErr)
+	}
 }
 
 func TestParsing(t *testing.T) {
@@ -26,7 +59,97 @@ func TestParsing(t *testing.T) {
 	}
 }
 
+func TestNewFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dict/data.noun": {Data: []byte(
+			"00000010 05 n 01 dog 0 001 @ 00000100 n 0000 | a member of the genus Canis\n" +
+				"00000100 05 n 01 canine 0 001 ~ 00000010 n 0000 | any of various fissiped mammals\n")},
+		"dict/.data.noun.swp": {Data: []byte("garbage")},
+		"other/data.noun":     {Data: []byte("garbage")},
+	}
+	h, err := NewFS(fsys, "dict")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found, err := h.Lookup(Criteria{Matching: "dog"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 || found[0].Gloss() != "a member of the genus Canis" {
+		t.Fatalf("expected to find dog, got %v", found)
+	}
+	if hypernyms := found[0].Related(Hypernym); len(hypernyms) != 1 || hypernyms[0].Word() != "canine" {
+		t.Errorf("expected canine as hypernym of dog")
+	}
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompressed(t *testing.T) {
+	needWordnet(t)
+	dir := sourceCodeRelPath(PathToWordnetDataFiles)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := fstest.MapFS{}
+	var tarBuf, zipBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	zw := zip.NewWriter(&zipBuf)
+	for _, e := range entries {
+		data, err := os.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz[e.Name()+".gz"] = &fstest.MapFile{Data: gzipped(t, data)}
+		tw.WriteHeader(&tar.Header{Name: "dict/" + e.Name(), Mode: 0644, Size: int64(len(data))})
+		tw.Write(data)
+		w, err := zw.Create("dict/" + e.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"gzip":   gz,
+		"tar.gz": {"wordnet.tar.gz": {Data: gzipped(t, tarBuf.Bytes())}},
+		"zip":    {"wordnet.zip": {Data: zipBuf.Bytes()}},
+	} {
+		h, err := newFS(fsys, ".", Options{POS: wnPOS})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if len(h.clusters) != len(wnInstance.clusters) {
+			t.Fatalf("%s: expected %d synsets, got %d", name, len(wnInstance.clusters), len(h.clusters))
+		}
+		for i := range wnInstance.clusters {
+			want, got := wnInstance.lookup(uint32(i)), h.lookup(uint32(i))
+			if got.ID() != want.ID() || got.DumpStr() != want.DumpStr() || got.SenseKey() != want.SenseKey() {
+				t.Fatalf("%s: synset %s differs:\n%s\n%s", name, want.ID(), got.DumpStr(), want.DumpStr())
+			}
+		}
+	}
+}
+
 func TestBasicLookup(t *testing.T) {
+	needWordnet(t)
 	// very basic test
 	found, err := wnInstance.Lookup(Criteria{Matching: "good"})
 	if err != nil {
@@ -46,6 +169,7 @@ func TestBasicLookup(t *testing.T) {
 }
 
 func TestLemma(t *testing.T) {
+	needWordnet(t)
 	found, err := wnInstance.Lookup(Criteria{Matching: "awesome", POS: []PartOfSpeech{Adjective}})
 	if err != nil {
 		t.Fatalf("%s", err)
@@ -79,6 +203,7 @@ func setContains(haystack, needles []string) bool {
 }
 
 func TestSynonyms(t *testing.T) {
+	needWordnet(t)
 	found, err := wnInstance.Lookup(Criteria{Matching: "yummy", POS: []PartOfSpeech{Adjective}})
 	if err != nil {
 		t.Fatalf("%s", err)
@@ -98,6 +223,7 @@ func TestSynonyms(t *testing.T) {
 }
 
 func TestAntonyms(t *testing.T) {
+	needWordnet(t)
 	found, err := wnInstance.Lookup(Criteria{Matching: "good", POS: []PartOfSpeech{Adjective}})
 	if err != nil {
 		t.Fatalf("%s", err)
@@ -116,6 +242,7 @@ func TestAntonyms(t *testing.T) {
 }
 
 func TestHypernyms(t *testing.T) {
+	needWordnet(t, Noun)
 	found, err := wnInstance.Lookup(Criteria{Matching: "jab", POS: []PartOfSpeech{Noun}})
 	if err != nil {
 		t.Fatalf("%s", err)
@@ -134,6 +261,7 @@ func TestHypernyms(t *testing.T) {
 }
 
 func TestHyponyms(t *testing.T) {
+	needWordnet(t, Noun)
 	found, err := wnInstance.Lookup(Criteria{Matching: "food", POS: []PartOfSpeech{Noun}})
 	if err != nil {
 		t.Fatalf("%s", err)
@@ -153,6 +281,7 @@ func TestHyponyms(t *testing.T) {
 }
 
 func TestIterate(t *testing.T) {
+	needWordnet(t, Noun)
 	count := 0
 	wnInstance.Iterate(PartOfSpeechList{Noun}, func(l Lookup) error {
 		count++
@@ -162,3 +291,269 @@ func TestIterate(t *testing.T) {
 		t.Errorf("Missing nouns!")
 	}
 }
+
+func TestSynsetID(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "yummy", POS: []PartOfSpeech{Adjective}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected one synonym cluster for yummy, got %d", len(found))
+	}
+	id := found[0].ID()
+	if len(id) != 10 || id[8:] != "-s" {
+		t.Errorf("malformed synset id for yummy: %s", id)
+	}
+	l, err := wnInstance.Synset(id)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if l.ID() != id || l.Gloss() != found[0].Gloss() {
+		t.Errorf("synset %s resolved to %s", id, l.ID())
+	}
+	if _, err := wnInstance.Synset(id[:8] + "-a"); err != nil {
+		t.Errorf("adjective satellites should resolve with an 'a' id: %s", err)
+	}
+	for _, bogus := range []string{"", "02084071", "0208407x-n", "02084071-q", "99999999-n"} {
+		if _, err := wnInstance.Synset(bogus); err == nil {
+			t.Errorf("expected error resolving %q", bogus)
+		}
+	}
+}
+
+func TestSatellites(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "yummy", POS: []PartOfSpeech{AdjectiveSatellite}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected one satellite cluster for yummy, got %d", len(found))
+	}
+	if !found[0].IsSatellite() || found[0].POS() != Adjective {
+		t.Errorf("expected yummy to be an adjective satellite")
+	}
+	head, ok := found[0].Head()
+	if !ok || head.Word() != "tasty" || head.IsSatellite() {
+		t.Errorf("expected tasty as head of yummy, got %s", head.Word())
+	}
+	if _, ok := head.Head(); ok {
+		t.Errorf("head adjectives have no head")
+	}
+
+	found, err = wnInstance.Lookup(Criteria{Matching: "good", POS: []PartOfSpeech{AdjectiveSatellite}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for _, f := range found {
+		if !f.IsSatellite() {
+			t.Errorf("expected only satellites, got %s", f.DumpStr())
+		}
+	}
+
+	var adjectives, satellites int
+	wnInstance.Iterate(PartOfSpeechList{Adjective}, func(l Lookup) error {
+		adjectives++
+		return nil
+	})
+	wnInstance.Iterate(PartOfSpeechList{AdjectiveSatellite}, func(l Lookup) error {
+		satellites++
+		return nil
+	})
+	if satellites == 0 || satellites >= adjectives {
+		t.Errorf("expected satellites to be a subset of adjectives (%d, %d)", satellites, adjectives)
+	}
+}
+
+func TestSyntacticMarkers(t *testing.T) {
+	needWordnet(t)
+	cases := map[string]SyntacticMarker{
+		"outback":       Attributive,
+		"ready to hand": Predicative,
+		"galore":        ImmediatelyPostnominal,
+	}
+	for word, marker := range cases {
+		found, err := wnInstance.Lookup(Criteria{Matching: word, POS: []PartOfSpeech{Adjective}})
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		gotMarker := false
+		for _, f := range found {
+			if f.SyntacticMarker() == marker {
+				gotMarker = true
+			}
+			if !setContains(f.Synonyms(), []string{word}) {
+				t.Errorf("marker not stripped from %s: %v", word, f.Synonyms())
+			}
+		}
+		if !gotMarker {
+			t.Errorf("expected %s marker for %s", marker, word)
+		}
+	}
+
+	found, err := wnInstance.Lookup(Criteria{Matching: "outback", Marker: Predicative})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no predicative outback, got %d", len(found))
+	}
+}
+
+func TestLexFile(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "run", LexFile: "verb.motion"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) == 0 {
+		t.Fatalf("expected motion senses of run")
+	}
+	for _, f := range found {
+		if f.LexFile() != "verb.motion" || f.POS() != Verb {
+			t.Errorf("unexpected sense of run: %s %s", f.LexFile(), f.Gloss())
+		}
+	}
+
+	count := 0
+	wnInstance.IterateCriteria(Criteria{LexFile: "verb.weather"}, func(l Lookup) error {
+		count++
+		if l.LexFile() != "verb.weather" {
+			t.Errorf("unexpected lexicographer file %s", l.LexFile())
+		}
+		return nil
+	})
+	if count == 0 {
+		t.Errorf("expected weather verbs")
+	}
+
+	wnInstance.Iterate(PartOfSpeechList{Adverb}, func(l Lookup) error {
+		if l.LexFile() != "adv.all" {
+			t.Fatalf("unexpected lexicographer file for adverb: %s", l.LexFile())
+		}
+		return nil
+	})
+}
+
+func TestSplitGloss(t *testing.T) {
+	def, examples := splitGloss(`having a pleasant taste; "a yummy dessert"; "delicious; very" - Mark Twain`)
+	if def != "having a pleasant taste" {
+		t.Errorf("unexpected definition: %q", def)
+	}
+	if len(examples) != 2 || examples[0] != "a yummy dessert" || examples[1] != "delicious; very" {
+		t.Errorf("unexpected examples: %q", examples)
+	}
+
+	def, examples = splitGloss("first sense; second sense")
+	if def != "first sense; second sense" || len(examples) != 0 {
+		t.Errorf("unexpected split of gloss without examples: %q %q", def, examples)
+	}
+}
+
+func TestDefinitionAndExamples(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "eat", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	usesEat := 0
+	for _, f := range found {
+		if f.Definition() == "" || (len(f.Examples()) > 0 && f.Definition() == f.Gloss()) {
+			t.Errorf("bad definition for %s: %q", f.Gloss(), f.Definition())
+		}
+		for _, e := range f.LemmaExamples()["eat"] {
+			usesEat++
+			if !setContains(f.Examples(), []string{e}) {
+				t.Errorf("%q is not an example of %s", e, f.Gloss())
+			}
+		}
+	}
+	if usesEat == 0 {
+		t.Errorf("expected examples using eat")
+	}
+}
+
+// Two senses of eat, the second sharing a synset with consume and eat
+// up, three of run and their tag counts
+var countsFS = fstest.MapFS{
+	"data.verb": {Data: []byte(
+		"00000010 34 v 01 eat 0 000 | take in solid food\n" +
+			"00000020 34 v 03 consume 0 eat_up 0 eat 1 000 | use up\n" +
+			"00000030 38 v 01 run 0 000 | move fast by using one's feet\n" +
+			"00000040 41 v 01 run 1 000 | be in charge of\n" +
+			"00000050 35 v 01 run 2 000 | cover by running\n")},
+	"index.verb": {Data: []byte(
+		"consume v 1 0 1 1 00000020  \n" +
+			"eat v 2 0 2 2 00000010 00000020  \n" +
+			"eat_up v 1 0 1 1 00000020  \n" +
+			"run v 3 0 3 1 00000030 00000040 00000050  \n")},
+	"cntlist.rev": {Data: []byte(
+		"consume%2:34:00:: 1 5\n" +
+			"eat%2:34:00:: 1 61\n" +
+			"eat%2:34:01:: 2 2\n" +
+			"eat_up%2:34:00:: 1 9\n" +
+			"run%2:38:00:: 1 41\n")},
+}
+
+func TestCounts(t *testing.T) {
+	h, err := NewFS(countsFS, ".")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found, err := h.Lookup(Criteria{Matching: "eat", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 2 || found[0].Count() != 61 || found[1].Count() != 2 {
+		t.Fatalf("unexpected tag counts for eat: %v", found)
+	}
+
+	found, err = h.Lookup(Criteria{Matching: "consume", POS: []PartOfSpeech{Verb}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected one sense of consume, got %d", len(found))
+	}
+	if got, want := found[0].SynonymsByCount(), []string{"eat up", "consume", "eat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synonyms by count: got %v, want %v", got, want)
+	}
+	if got, want := found[0].Synonyms(), []string{"consume", "eat up", "eat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synonyms reordered: got %v, want %v", got, want)
+	}
+
+	if f := h.Familiarity("run", Verb); f.Polysemy != 3 || f.Level != "uncommon" {
+		t.Errorf("unexpected familiarity for run: %+v", f)
+	}
+	if f := h.Familiarity("eat", Verb); f.Polysemy != 2 || f.Level != "rare" {
+		t.Errorf("unexpected familiarity for eat: %+v", f)
+	}
+	if f := h.Familiarity("xyzzy", Verb); f.Polysemy != 0 || f.Level != "extremely rare" {
+		t.Errorf("unexpected familiarity for xyzzy: %+v", f)
+	}
+}
+
+func TestInternedStrings(t *testing.T) {
+	needWordnet(t)
+	found, err := wnInstance.Lookup(Criteria{Matching: "  Good ", POS: []PartOfSpeech{Adjective}})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found) == 0 {
+		t.Fatalf("expected to find good")
+	}
+	key, ok := wnInstance.search(wnInstance.index, "good")
+	if !ok {
+		t.Fatalf("expected good in the index")
+	}
+	for _, f := range found {
+		// the word found is the search string, as it always has been
+		if f.Word() != "  Good " {
+			t.Errorf("expected the search string, got %q", f.Word())
+		}
+		if w := f.wordRec(); w == nil || w.word != key.key {
+			t.Errorf("expected good to be stored once")
+		}
+	}
+}